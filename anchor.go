@@ -1,12 +1,26 @@
 package disc
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/bwmarrin/discordgo"
 )
 
+// bulkDeleteMaxAge is how old messages may be for Discord to accept them in a bulk delete, with a margin
+// for the time the request takes.
+const bulkDeleteMaxAge = time.Hour*24*14 - time.Minute
+
 type AnchorOpts struct {
 	ForceClear         bool
 	MaxAllowedMessages int
+	Debounce           time.Duration
+	Key                string
+	Unarchive          bool
+	Pin                bool
+	Crosspost          bool
+	MaxDeletes         int
 }
 
 type AnchorOptFunc func(*AnchorOpts)
@@ -15,9 +29,11 @@ func DefaultAnchorOpts() *AnchorOpts {
 	return &AnchorOpts{
 		ForceClear:         false,
 		MaxAllowedMessages: 1,
+		Debounce:           time.Second * 2,
 	}
 }
 
+// ForceClearAnchorOpt deletes every message of the channel, the bot's included. Registered anchors reject it.
 func ForceClearAnchorOpt() AnchorOptFunc {
 	return func(opts *AnchorOpts) {
 		opts.ForceClear = true
@@ -30,91 +46,319 @@ func MaxAllowedMessagesAnchorOpt(mam int) AnchorOptFunc {
 	}
 }
 
+// DebounceAnchorOpt sets how long a registered anchor waits for the channel to settle before it is enforced.
+func DebounceAnchorOpt(debounce time.Duration) AnchorOptFunc {
+	return func(opts *AnchorOpts) {
+		opts.Debounce = debounce
+	}
+}
+
+// KeyAnchorOpt identifies the anchor by a stable key tracked in the client's AnchorStore, the anchor fails
+// with ErrAnchorStoreRequired until one is set. Keyed anchors only reconcile the messages they posted
+// themselves, so several of them and other bot messages can share a channel. MaxAllowedMessagesAnchorOpt is
+// ignored for them.
+func KeyAnchorOpt(key string) AnchorOptFunc {
+	return func(opts *AnchorOpts) {
+		opts.Key = key
+	}
+}
+
+// MaxDeletesAnchorOpt refuses to reconcile the anchor when it would delete more than n messages, the
+// anchor fails with an *AnchorMaxDeletesError before deleting them. Zero, the default, allows any amount.
+func MaxDeletesAnchorOpt(n int) AnchorOptFunc {
+	return func(opts *AnchorOpts) {
+		opts.MaxDeletes = n
+	}
+}
+
+type AnchorMaxDeletesError struct {
+	Limit   int
+	Planned int
+}
+
+func (e *AnchorMaxDeletesError) Error() string {
+	return fmt.Sprintf("anchor would delete %d messages, more than the allowed %d", e.Planned, e.Limit)
+}
+
+// AnchorEdit is an existing message edited to show an anchor message.
+type AnchorEdit struct {
+	Message *discordgo.Message
+	Send    *discordgo.MessageSend
+}
+
+// AnchorPlan lists the writes reconciling an anchor. Deleted messages carry their author and timestamp.
+type AnchorPlan struct {
+	Deletes []*discordgo.Message
+	Edits   []AnchorEdit
+	Sends   []*discordgo.MessageSend
+	// kept are the existing messages showing the anchor after the edits, sent messages follow them.
+	kept []*discordgo.Message
+}
+
 func (c *Client) Anchor(channelID string, msg *discordgo.MessageSend, opts ...AnchorOptFunc) (err error) {
 	o := DefaultAnchorOpts()
 	for _, opt := range opts {
 		opt(o)
 	}
-	validMsgs := 0
-	for {
-		msgs, err := c.sess.ChannelMessages(channelID, 100, "", "", "")
+	return c.anchor(channelID, []*discordgo.MessageSend{msg}, o)
+}
+
+func (c *Client) Anchors(channelID string, msgs []*discordgo.MessageSend, opts ...AnchorOptFunc) (err error) {
+	o := DefaultAnchorOpts()
+	o.MaxAllowedMessages = len(msgs)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return c.anchor(channelID, msgs, o)
+}
+
+// PlanAnchor returns what Anchor would delete, edit and send without performing any writes.
+func (c *Client) PlanAnchor(channelID string, msg *discordgo.MessageSend, opts ...AnchorOptFunc) (plan *AnchorPlan, err error) {
+	o := DefaultAnchorOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return c.planAnchor(channelID, []*discordgo.MessageSend{msg}, o)
+}
+
+// PlanAnchors returns what Anchors would delete, edit and send without performing any writes.
+func (c *Client) PlanAnchors(channelID string, msgs []*discordgo.MessageSend, opts ...AnchorOptFunc) (plan *AnchorPlan, err error) {
+	o := DefaultAnchorOpts()
+	o.MaxAllowedMessages = len(msgs)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return c.planAnchor(channelID, msgs, o)
+}
+
+func (c *Client) anchor(channelID string, msgs []*discordgo.MessageSend, o *AnchorOpts) (err error) {
+	if o.Unarchive {
+		err = c.anchorUnarchive(channelID)
 		if err != nil {
 			return err
 		}
-		validMsgs = 0
-		invaldMsgIDs := []string{}
-		for _, m := range msgs {
-			if o.ForceClear || m.Author.ID != c.sess.State.User.ID {
-				invaldMsgIDs = append(invaldMsgIDs, m.ID)
-			} else {
-				validMsgs++
-			}
+	}
+	var plan *AnchorPlan
+	deleted := 0
+	for {
+		plan, err = c.planAnchor(channelID, msgs, o)
+		if err != nil {
+			return err
 		}
-		if len(invaldMsgIDs) == 0 {
+		if len(plan.Deletes) == 0 {
 			break
 		}
-		err = c.anchorDeleteMessages(channelID, invaldMsgIDs)
+		deleted += len(plan.Deletes)
+		if o.MaxDeletes > 0 && deleted > o.MaxDeletes {
+			return &AnchorMaxDeletesError{Limit: o.MaxDeletes, Planned: deleted}
+		}
+		err = c.anchorDeleteMessages(channelID, plan.Deletes)
+		if err != nil {
+			return err
+		}
+	}
+	for _, edit := range plan.Edits {
+		_, err = c.sess.ChannelMessageEditComplex(anchorMessageEdit(channelID, edit.Message.ID, edit.Send))
 		if err != nil {
 			return err
 		}
 	}
-	if validMsgs < o.MaxAllowedMessages {
-		_, err = c.sess.ChannelMessageSendComplex(channelID, msg)
+	anchored := plan.kept
+	sent := []*discordgo.Message{}
+	for _, msg := range plan.Sends {
+		var m *discordgo.Message
+		m, err = c.sess.ChannelMessageSendComplex(channelID, msg)
+		if err != nil {
+			break
+		}
+		anchored = append(anchored, m)
+		sent = append(sent, m)
+	}
+	if o.Key != "" {
+		msgIDs := make([]string, len(anchored))
+		for i, m := range anchored {
+			msgIDs[i] = m.ID
+		}
+		if storeErr := c.anchorStore.Set(channelID, o.Key, msgIDs); storeErr != nil {
+			return errors.Join(err, storeErr)
+		}
+	}
+	if err != nil {
+		return err
 	}
-	return
+	return c.anchorPublish(channelID, anchored, sent, o)
 }
 
-func (c *Client) anchorDeleteMessages(channelID string, invalidMsgIDs []string) (err error) {
-	err = c.sess.ChannelMessagesBulkDelete(channelID, invalidMsgIDs)
+// planAnchor works out which messages to delete, edit and send. Bot messages already showing an anchor are
+// kept, the others are edited in order when the channel holds no more anchors than given. When more
+// messages are allowed, the channel is shared with other anchors and bot messages are never edited.
+// Messages owned by keyed anchors are left to them. Starter messages of threads and forum posts are
+// never deleted, pin notifications always are.
+func (c *Client) planAnchor(channelID string, msgs []*discordgo.MessageSend, o *AnchorOpts) (plan *AnchorPlan, err error) {
+	if o.Key != "" && c.anchorStore == nil {
+		return nil, ErrAnchorStoreRequired
+	}
+	channelMsgs, err := c.channelHistory(channelID)
 	if err != nil {
-		for _, msgID := range invalidMsgIDs {
-			err = c.sess.ChannelMessageDelete(channelID, msgID)
-			if err != nil {
-				return err
+		return nil, err
+	}
+	keyed := map[string][]string{}
+	if c.anchorStore != nil {
+		keyed, err = c.anchorStore.Channel(channelID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ownedBy := map[string]string{}
+	for key, msgIDs := range keyed {
+		for _, msgID := range msgIDs {
+			ownedBy[msgID] = key
+		}
+	}
+	plan = &AnchorPlan{}
+	valid := []*discordgo.Message{}
+	for i := len(channelMsgs) - 1; i >= 0; i-- {
+		m := channelMsgs[i]
+		key, owned := ownedBy[m.ID]
+		bot := m.Author != nil && m.Author.ID == c.sess.State.User.ID
+		starter := isThreadStarter(channelID, m)
+		switch {
+		case m.Type == discordgo.MessageTypeChannelPinnedMessage:
+			plan.Deletes = append(plan.Deletes, m)
+		case starter && !bot:
+		case (o.ForceClear && !starter) || !bot:
+			plan.Deletes = append(plan.Deletes, m)
+		case o.Key != "" && key == o.Key:
+			valid = append(valid, m)
+		case o.Key == "" && !owned:
+			valid = append(valid, m)
+		}
+	}
+	if o.Key != "" {
+		return planKeyedAnchor(plan, valid, msgs), nil
+	}
+	used := make([]bool, len(valid))
+	pending := []*discordgo.MessageSend{}
+	for _, msg := range msgs {
+		matched := false
+		for i, m := range valid {
+			if !used[i] && anchorMessageEqual(m, msg) {
+				used[i] = true
+				matched = true
+				plan.kept = append(plan.kept, m)
+				break
+			}
+		}
+		if !matched {
+			pending = append(pending, msg)
+		}
+	}
+	count := len(valid)
+	for _, msg := range pending {
+		edited := false
+		if o.MaxAllowedMessages <= len(msgs) {
+			for i, m := range valid {
+				if !used[i] {
+					used[i] = true
+					edited = true
+					plan.Edits = append(plan.Edits, AnchorEdit{Message: m, Send: msg})
+					plan.kept = append(plan.kept, m)
+					break
+				}
 			}
 		}
+		if !edited && count < o.MaxAllowedMessages {
+			plan.Sends = append(plan.Sends, msg)
+			count++
+		}
 	}
-	return nil
+	return plan, nil
 }
 
-func (c *Client) Anchors(channelID string, msgs []*discordgo.MessageSend, opts ...AnchorOptFunc) (err error) {
-	o := DefaultAnchorOpts()
-	o.MaxAllowedMessages = len(msgs)
-	for _, opt := range opts {
-		opt(o)
+// planKeyedAnchor lines the owned messages up with the anchor's messages by position so their order is kept,
+// owned messages left over are deleted and missing ones are sent.
+func planKeyedAnchor(plan *AnchorPlan, owned []*discordgo.Message, msgs []*discordgo.MessageSend) *AnchorPlan {
+	if len(owned) > len(msgs) {
+		plan.Deletes = append(plan.Deletes, owned[len(msgs):]...)
+		owned = owned[:len(msgs)]
+	}
+	for i, msg := range msgs {
+		if i >= len(owned) {
+			plan.Sends = append(plan.Sends, msg)
+			continue
+		}
+		plan.kept = append(plan.kept, owned[i])
+		if !anchorMessageEqual(owned[i], msg) {
+			plan.Edits = append(plan.Edits, AnchorEdit{Message: owned[i], Send: msg})
+		}
+	}
+	return plan
+}
+
+func anchorMessageEdit(channelID string, messageID string, msg *discordgo.MessageSend) *discordgo.MessageEdit {
+	edit := discordgo.NewMessageEdit(channelID, messageID)
+	embeds := anchorEmbeds(msg)
+	if embeds == nil {
+		embeds = []*discordgo.MessageEmbed{}
 	}
-	validMsgs := 0
+	components := msg.Components
+	if components == nil {
+		components = []discordgo.MessageComponent{}
+	}
+	edit.Content = &msg.Content
+	edit.Embeds = &embeds
+	edit.Components = &components
+	edit.AllowedMentions = msg.AllowedMentions
+	return edit
+}
+
+// channelHistory pages through every message of the channel with before cursors, newest first.
+func (c *Client) channelHistory(channelID string) (msgs []*discordgo.Message, err error) {
+	beforeID := ""
 	for {
-		msgs, err := c.sess.ChannelMessages(channelID, 100, "", "", "")
+		page, err := c.sess.ChannelMessages(channelID, 100, beforeID, "", "")
 		if err != nil {
-			return err
+			return nil, err
 		}
-		validMsgs = 0
-		invaldMsgIDs := []string{}
-		for _, m := range msgs {
-			if o.ForceClear || m.Author.ID != c.sess.State.User.ID {
-				invaldMsgIDs = append(invaldMsgIDs, m.ID)
-			} else {
-				validMsgs++
-			}
+		msgs = append(msgs, page...)
+		if len(page) < 100 {
+			return msgs, nil
 		}
-		if len(invaldMsgIDs) == 0 {
-			break
+		beforeID = page[len(page)-1].ID
+	}
+}
+
+// anchorDeleteMessages bulk deletes the messages young enough for it in batches of 100 and deletes the
+// older ones one by one. The single deletes are sent sequentially so discordgo's ratelimiter paces them by
+// the bucket headers of the delete route.
+func (c *Client) anchorDeleteMessages(channelID string, msgs []*discordgo.Message) (err error) {
+	young, old := []string{}, []string{}
+	for _, m := range msgs {
+		c.anchorDeletes.add(m.ID)
+		if time.Since(m.Timestamp) < bulkDeleteMaxAge {
+			young = append(young, m.ID)
+		} else {
+			old = append(old, m.ID)
 		}
-		err = c.sess.ChannelMessagesBulkDelete(channelID, invaldMsgIDs)
+	}
+	for len(young) > 0 {
+		batch := young[:min(len(young), 100)]
+		young = young[len(batch):]
+		err = c.sess.ChannelMessagesBulkDelete(channelID, batch)
 		if err != nil {
-			return err
+			old = append(old, batch...)
 		}
 	}
-	for _, msg := range msgs {
-		if validMsgs >= o.MaxAllowedMessages {
-			break
-		}
-		_, err = c.sess.ChannelMessageSendComplex(channelID, msg)
-		if err != nil {
+	for _, msgID := range old {
+		err = c.sess.ChannelMessageDelete(channelID, msgID)
+		if err != nil && !isRESTErrCode(err, discordgo.ErrCodeUnknownMessage) {
 			return err
 		}
-		validMsgs++
 	}
-	return
+	return nil
+}
+
+func isRESTErrCode(err error, code int) bool {
+	var restErr *discordgo.RESTError
+	return errors.As(err, &restErr) && restErr.Message != nil && restErr.Message.Code == code
 }
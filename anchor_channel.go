@@ -0,0 +1,89 @@
+package disc
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// UnarchiveAnchorOpt unarchives the thread or forum post the anchor lives in before reconciling it.
+func UnarchiveAnchorOpt() AnchorOptFunc {
+	return func(opts *AnchorOpts) {
+		opts.Unarchive = true
+	}
+}
+
+// PinAnchorOpt pins the anchored messages. When the anchor is the starter message of a forum post, the post
+// is pinned in its forum as well.
+func PinAnchorOpt() AnchorOptFunc {
+	return func(opts *AnchorOpts) {
+		opts.Pin = true
+	}
+}
+
+// CrosspostAnchorOpt publishes newly sent anchor messages to the channels following the announcement
+// channel. Edits of published messages reach the followers on their own.
+func CrosspostAnchorOpt() AnchorOptFunc {
+	return func(opts *AnchorOpts) {
+		opts.Crosspost = true
+	}
+}
+
+// isThreadStarter reports whether the message started the thread, forum post starter messages share
+// their ID with the post.
+func isThreadStarter(channelID string, m *discordgo.Message) bool {
+	return m.ID == channelID || m.Type == discordgo.MessageTypeThreadStarterMessage
+}
+
+func (c *Client) anchorUnarchive(channelID string) (err error) {
+	ch, err := c.sess.Channel(channelID)
+	if err != nil {
+		return err
+	}
+	if !ch.IsThread() || ch.ThreadMetadata == nil || !ch.ThreadMetadata.Archived {
+		return nil
+	}
+	archived := false
+	_, err = c.sess.ChannelEditComplex(channelID, &discordgo.ChannelEdit{
+		Archived: &archived,
+	})
+	return err
+}
+
+func (c *Client) anchorPublish(channelID string, anchored []*discordgo.Message, sent []*discordgo.Message, o *AnchorOpts) (err error) {
+	if o.Crosspost {
+		for _, m := range sent {
+			_, err = c.sess.ChannelMessageCrosspost(channelID, m.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if !o.Pin {
+		return nil
+	}
+	for _, m := range anchored {
+		if m.ID == channelID {
+			err = c.anchorPinPost(channelID)
+		} else if !m.Pinned {
+			err = c.sess.ChannelMessagePin(channelID, m.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) anchorPinPost(channelID string) (err error) {
+	ch, err := c.sess.Channel(channelID)
+	if err != nil {
+		return err
+	}
+	if ch.Flags&discordgo.ChannelFlagPinned != 0 {
+		return nil
+	}
+	flags := ch.Flags | discordgo.ChannelFlagPinned
+	_, err = c.sess.ChannelEditComplex(channelID, &discordgo.ChannelEdit{
+		Flags: &flags,
+	})
+	return err
+}
@@ -0,0 +1,403 @@
+package disc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"gopkg.in/yaml.v3"
+)
+
+// AnchorDefinitions describe anchors in a JSON or YAML file so they can change without a deploy. Every
+// string of a message is a Go template executed with AnchorTemplateData.
+type AnchorDefinitions struct {
+	Vars    map[string]string  `json:"vars" yaml:"vars"`
+	Anchors []AnchorDefinition `json:"anchors" yaml:"anchors"`
+}
+
+type AnchorDefinition struct {
+	// Name identifies the definition across reloads and is the anchor key unless options set another.
+	Name      string                    `json:"name" yaml:"name"`
+	ChannelID string                    `json:"channel_id" yaml:"channel_id"`
+	Messages  []AnchorMessageDefinition `json:"messages" yaml:"messages"`
+	Options   AnchorDefinitionOptions   `json:"options" yaml:"options"`
+}
+
+type AnchorMessageDefinition struct {
+	Content string                   `json:"content" yaml:"content"`
+	Embeds  []AnchorEmbedDefinition  `json:"embeds" yaml:"embeds"`
+	Buttons []AnchorButtonDefinition `json:"buttons" yaml:"buttons"`
+}
+
+type AnchorEmbedDefinition struct {
+	Title       string                       `json:"title" yaml:"title"`
+	Description string                       `json:"description" yaml:"description"`
+	URL         string                       `json:"url" yaml:"url"`
+	Color       int                          `json:"color" yaml:"color"`
+	Footer      string                       `json:"footer" yaml:"footer"`
+	Image       string                       `json:"image" yaml:"image"`
+	Thumbnail   string                       `json:"thumbnail" yaml:"thumbnail"`
+	Fields      []AnchorEmbedFieldDefinition `json:"fields" yaml:"fields"`
+}
+
+type AnchorEmbedFieldDefinition struct {
+	Name   string `json:"name" yaml:"name"`
+	Value  string `json:"value" yaml:"value"`
+	Inline bool   `json:"inline" yaml:"inline"`
+}
+
+type AnchorButtonDefinition struct {
+	Label    string `json:"label" yaml:"label"`
+	Style    string `json:"style" yaml:"style"`
+	CustomID string `json:"custom_id" yaml:"custom_id"`
+	URL      string `json:"url" yaml:"url"`
+	Disabled bool   `json:"disabled" yaml:"disabled"`
+}
+
+// AnchorDefinitionOptions map to the anchor options. MaxAllowedMessages and ForceClear are rejected by
+// Validate, definitions are registered keyed anchors which ignore the first and cannot use the second.
+type AnchorDefinitionOptions struct {
+	Key                string `json:"key" yaml:"key"`
+	ForceClear         bool   `json:"force_clear" yaml:"force_clear"`
+	MaxAllowedMessages int    `json:"max_allowed_messages" yaml:"max_allowed_messages"`
+	Debounce           string `json:"debounce" yaml:"debounce"`
+	Unarchive          bool   `json:"unarchive" yaml:"unarchive"`
+	Pin                bool   `json:"pin" yaml:"pin"`
+	Crosspost          bool   `json:"crosspost" yaml:"crosspost"`
+	MaxDeletes         int    `json:"max_deletes" yaml:"max_deletes"`
+}
+
+// AnchorTemplateData is passed to the templates of an anchor definition. Roles maps role names to their
+// mentions, the role template function does the same.
+type AnchorTemplateData struct {
+	GuildID     string
+	GuildName   string
+	MemberCount int
+	Roles       map[string]string
+	Vars        map[string]string
+}
+
+var anchorButtonStyles = map[string]discordgo.ButtonStyle{
+	"":          discordgo.PrimaryButton,
+	"primary":   discordgo.PrimaryButton,
+	"secondary": discordgo.SecondaryButton,
+	"success":   discordgo.SuccessButton,
+	"danger":    discordgo.DangerButton,
+	"link":      discordgo.LinkButton,
+}
+
+// LoadAnchorDefinitions reads the definitions from a .json, .yaml or .yml file.
+func LoadAnchorDefinitions(path string) (defs *AnchorDefinitions, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAnchorDefinitions(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// ParseAnchorDefinitions parses and validates the definitions, format is either json or yaml.
+func ParseAnchorDefinitions(data []byte, format string) (defs *AnchorDefinitions, err error) {
+	defs = &AnchorDefinitions{}
+	switch strings.ToLower(format) {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(defs)
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(defs)
+	default:
+		return nil, fmt.Errorf("unsupported anchor definitions format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = defs.Validate(); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+// Validate checks the definitions are complete, the rendered messages are checked against the Discord limits
+// when they are applied.
+func (defs *AnchorDefinitions) Validate() error {
+	errs := []error{}
+	names := map[string]bool{}
+	keys := map[string]string{}
+	for i, def := range defs.Anchors {
+		path := fmt.Sprintf("anchors[%d]", i)
+		if def.Name == "" {
+			errs = append(errs, fmt.Errorf("%s: name is required", path))
+		} else if names[def.Name] {
+			errs = append(errs, fmt.Errorf("%s: duplicate name %q", path, def.Name))
+		}
+		names[def.Name] = true
+		if def.ChannelID == "" {
+			errs = append(errs, fmt.Errorf("%s: channel_id is required", path))
+		}
+		if len(def.Messages) == 0 {
+			errs = append(errs, fmt.Errorf("%s: messages are required", path))
+		}
+		if other, ok := keys[anchorRegistryKey(def.ChannelID, def.key())]; ok {
+			errs = append(errs, fmt.Errorf("%s: key %q is already used by %q in the channel", path, def.key(), other))
+		} else {
+			keys[anchorRegistryKey(def.ChannelID, def.key())] = def.Name
+		}
+		if def.Options.ForceClear {
+			errs = append(errs, fmt.Errorf("%s.options.force_clear: not supported, definitions are registered anchors", path))
+		}
+		if def.Options.MaxAllowedMessages != 0 {
+			errs = append(errs, fmt.Errorf("%s.options.max_allowed_messages: not supported, definitions are keyed anchors", path))
+		}
+		if def.Options.Debounce != "" {
+			if _, err := time.ParseDuration(def.Options.Debounce); err != nil {
+				errs = append(errs, fmt.Errorf("%s.options.debounce: %w", path, err))
+			}
+		}
+		for j, msg := range def.Messages {
+			msgPath := fmt.Sprintf("%s.messages[%d]", path, j)
+			if msg.Content == "" && len(msg.Embeds) == 0 {
+				errs = append(errs, fmt.Errorf("%s: content or embeds are required", msgPath))
+			}
+			if len(msg.Buttons) > 25 {
+				errs = append(errs, fmt.Errorf("%s: at most 25 buttons are allowed", msgPath))
+			}
+			for k, btn := range msg.Buttons {
+				btnPath := fmt.Sprintf("%s.buttons[%d]", msgPath, k)
+				style, ok := anchorButtonStyles[btn.Style]
+				switch {
+				case !ok:
+					errs = append(errs, fmt.Errorf("%s: unknown style %q", btnPath, btn.Style))
+				case style == discordgo.LinkButton && btn.URL == "":
+					errs = append(errs, fmt.Errorf("%s: link buttons require a url", btnPath))
+				case style != discordgo.LinkButton && btn.CustomID == "":
+					errs = append(errs, fmt.Errorf("%s: custom_id is required", btnPath))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AnchorOpts returns the anchor options of the definition.
+func (def AnchorDefinition) AnchorOpts() (opts []AnchorOptFunc) {
+	opts = append(opts, KeyAnchorOpt(def.key()))
+	if debounce, err := time.ParseDuration(def.Options.Debounce); err == nil {
+		opts = append(opts, DebounceAnchorOpt(debounce))
+	}
+	if def.Options.Unarchive {
+		opts = append(opts, UnarchiveAnchorOpt())
+	}
+	if def.Options.Pin {
+		opts = append(opts, PinAnchorOpt())
+	}
+	if def.Options.Crosspost {
+		opts = append(opts, CrosspostAnchorOpt())
+	}
+	if def.Options.MaxDeletes > 0 {
+		opts = append(opts, MaxDeletesAnchorOpt(def.Options.MaxDeletes))
+	}
+	return opts
+}
+
+// Render executes the templates of the definition and validates the messages against the Discord limits.
+func (def AnchorDefinition) Render(data *AnchorTemplateData) (msgs []*discordgo.MessageSend, err error) {
+	r := &anchorRenderer{data: data}
+	for i, msgDef := range def.Messages {
+		msg := &discordgo.MessageSend{
+			Content: r.execute(msgDef.Content),
+		}
+		for _, embedDef := range msgDef.Embeds {
+			embed := &discordgo.MessageEmbed{
+				Title:       r.execute(embedDef.Title),
+				Description: r.execute(embedDef.Description),
+				URL:         r.execute(embedDef.URL),
+				Color:       embedDef.Color,
+			}
+			if embedDef.Footer != "" {
+				embed.Footer = &discordgo.MessageEmbedFooter{Text: r.execute(embedDef.Footer)}
+			}
+			if embedDef.Image != "" {
+				embed.Image = &discordgo.MessageEmbedImage{URL: r.execute(embedDef.Image)}
+			}
+			if embedDef.Thumbnail != "" {
+				embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: r.execute(embedDef.Thumbnail)}
+			}
+			for _, fieldDef := range embedDef.Fields {
+				embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+					Name:   r.execute(fieldDef.Name),
+					Value:  r.execute(fieldDef.Value),
+					Inline: fieldDef.Inline,
+				})
+			}
+			msg.Embeds = append(msg.Embeds, embed)
+		}
+		row := discordgo.ActionsRow{}
+		for _, btnDef := range msgDef.Buttons {
+			if len(row.Components) == 5 {
+				msg.Components = append(msg.Components, row)
+				row = discordgo.ActionsRow{}
+			}
+			row.Components = append(row.Components, discordgo.Button{
+				Label:    r.execute(btnDef.Label),
+				Style:    anchorButtonStyles[btnDef.Style],
+				CustomID: btnDef.CustomID,
+				URL:      r.execute(btnDef.URL),
+				Disabled: btnDef.Disabled,
+			})
+		}
+		if len(row.Components) > 0 {
+			msg.Components = append(msg.Components, row)
+		}
+		if r.err != nil {
+			return nil, fmt.Errorf("anchor %q message %d: %w", def.Name, i, r.err)
+		}
+		if err = ValidateMessage(msg.Content, msg.Embeds); err != nil {
+			return nil, fmt.Errorf("anchor %q message %d: %w", def.Name, i, err)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+type anchorRenderer struct {
+	data *AnchorTemplateData
+	err  error
+}
+
+func (r *anchorRenderer) execute(text string) string {
+	if r.err != nil || !strings.Contains(text, "{{") {
+		return text
+	}
+	tmpl, err := template.New("").Option("missingkey=error").Funcs(template.FuncMap{
+		"role": func(name string) (string, error) {
+			mention, ok := r.data.Roles[name]
+			if !ok {
+				return "", fmt.Errorf("unknown role %q", name)
+			}
+			return mention, nil
+		},
+	}).Parse(text)
+	if err != nil {
+		r.err = err
+		return text
+	}
+	buf := &bytes.Buffer{}
+	if err = tmpl.Execute(buf, r.data); err != nil {
+		r.err = err
+		return text
+	}
+	return buf.String()
+}
+
+// AnchorTemplateData returns the template data of the guild the channel belongs to.
+func (c *Client) AnchorTemplateData(channelID string, vars map[string]string) (data *AnchorTemplateData, err error) {
+	ch, err := c.sess.State.Channel(channelID)
+	if err != nil {
+		ch, err = c.sess.Channel(channelID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	data = &AnchorTemplateData{
+		GuildID: ch.GuildID,
+		Roles:   map[string]string{},
+		Vars:    vars,
+	}
+	if ch.GuildID == "" {
+		return data, nil
+	}
+	guild, err := c.sess.GuildWithCounts(ch.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	data.GuildName = guild.Name
+	data.MemberCount = guild.ApproximateMemberCount
+	for _, role := range guild.Roles {
+		data.Roles[role.Name] = role.Mention()
+	}
+	return data, nil
+}
+
+// ApplyAnchorDefinitions registers the anchors of the definitions that changed since they were last applied
+// and unregisters the ones that were removed. The messages of removed anchors are left in their channels.
+// Definitions are keyed anchors, set a persistent AnchorStore so their messages are recognised after a
+// restart instead of being posted again.
+func (c *Client) ApplyAnchorDefinitions(defs *AnchorDefinitions) (err error) {
+	if err = defs.Validate(); err != nil {
+		return err
+	}
+	if c.anchorStore == nil {
+		return ErrAnchorStoreRequired
+	}
+	applied := map[string]bool{}
+	errs := []error{}
+	for _, def := range defs.Anchors {
+		applied[def.Name] = true
+		prev, ok := c.anchorDefinitions.Get(def.Name)
+		if ok && reflect.DeepEqual(prev.def, def) && reflect.DeepEqual(prev.vars, defs.Vars) {
+			continue
+		}
+		if ok && (prev.def.ChannelID != def.ChannelID || prev.key() != def.key()) {
+			c.UnregisterKeyedAnchor(prev.def.ChannelID, prev.key())
+		}
+		data, err := c.AnchorTemplateData(def.ChannelID, defs.Vars)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("anchor %q: %w", def.Name, err))
+			continue
+		}
+		msgs, err := def.Render(data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err = c.RegisterAnchors(def.ChannelID, msgs, def.AnchorOpts()...); err != nil {
+			errs = append(errs, fmt.Errorf("anchor %q: %w", def.Name, err))
+			continue
+		}
+		c.anchorDefinitions.Set(def.Name, appliedAnchorDefinition{def: def, vars: defs.Vars})
+	}
+	for _, name := range c.anchorDefinitions.Keys() {
+		if applied[name] {
+			continue
+		}
+		prev := c.anchorDefinitions.MustGet(name)
+		c.UnregisterKeyedAnchor(prev.def.ChannelID, prev.key())
+		c.anchorDefinitions.Delete(name)
+	}
+	return errors.Join(errs...)
+}
+
+// ReloadAnchorDefinitions loads the definitions file again and applies the changed definitions.
+func (c *Client) ReloadAnchorDefinitions(path string) (err error) {
+	defs, err := LoadAnchorDefinitions(path)
+	if err != nil {
+		return err
+	}
+	return c.ApplyAnchorDefinitions(defs)
+}
+
+type appliedAnchorDefinition struct {
+	def  AnchorDefinition
+	vars map[string]string
+}
+
+func (a appliedAnchorDefinition) key() string {
+	return a.def.key()
+}
+
+func (def AnchorDefinition) key() string {
+	if def.Options.Key != "" {
+		return def.Options.Key
+	}
+	return def.Name
+}
@@ -0,0 +1,122 @@
+package disc_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/matryer/is"
+	"github.com/stevo-go-utils/disc"
+)
+
+const testAnchorDefinitions = `
+vars:
+  support: "#support"
+anchors:
+  - name: rules
+    channel_id: channel
+    messages:
+      - content: "Welcome to {{.GuildName}}, {{.MemberCount}} members. Ask in {{.Vars.support}}."
+        embeds:
+          - title: Rules
+            description: "Ping {{role \"Mods\"}} when needed."
+        buttons:
+          - label: Accept
+            custom_id: accept
+          - label: Docs
+            style: link
+            url: https://example.com
+    options:
+      pin: true
+  - name: verify
+    channel_id: channel
+    messages:
+      - content: Verify here
+`
+
+func TestAnchorDefinitions(t *testing.T) {
+	is := is.New(t)
+	path := filepath.Join(t.TempDir(), "anchors.yaml")
+	is.NoErr(os.WriteFile(path, []byte(testAnchorDefinitions), 0o644))
+	f := newFakeChannel("channel")
+	f.channel.GuildID = "guild"
+	f.guild = &discordgo.Guild{ID: "guild", Name: "Gophers", ApproximateMemberCount: 42, Roles: []*discordgo.Role{{ID: "7", Name: "Mods"}}}
+	f.add("user", "hello", time.Minute)
+	c := newFakeChannelClient(t, f)
+	c.SetAnchorStore(disc.NewMemoryAnchorStore())
+
+	is.NoErr(c.ReloadAnchorDefinitions(path))
+	is.Equal(f.contents(), []string{"Welcome to Gophers, 42 members. Ask in #support.", "Verify here"})
+	is.Equal(f.msgs[0].Embeds[0].Description, "Ping <@&7> when needed.")
+	is.Equal(len(f.msgs[0].Components), 1)
+	is.True(f.msgs[0].Pinned)
+	is.Equal(len(c.RegisteredAnchorChannels()), 1)
+
+	patches := f.requestCount("PATCH")
+	is.NoErr(c.ReloadAnchorDefinitions(path))
+	is.Equal(f.requestCount("PATCH"), patches) // unchanged definitions are not reconciled
+
+	defs, err := disc.LoadAnchorDefinitions(path)
+	is.NoErr(err)
+	defs.Anchors[1].Messages[0].Content = "Verify below"
+	defs.Anchors = defs.Anchors[1:]
+	is.NoErr(c.ApplyAnchorDefinitions(defs))
+	is.Equal(f.contents(), []string{"Welcome to Gophers, 42 members. Ask in #support.", "Verify below"})
+}
+
+func TestAnchorDefinitionsRestart(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "anchors.yaml")
+	is.NoErr(os.WriteFile(path, []byte(testAnchorDefinitions), 0o644))
+	f := newFakeChannel("channel")
+	f.channel.GuildID = "guild"
+	f.guild = &discordgo.Guild{ID: "guild", Name: "Gophers", Roles: []*discordgo.Role{{ID: "7", Name: "Mods"}}}
+	c := newFakeChannelClient(t, f)
+	is.Equal(c.ReloadAnchorDefinitions(path), disc.ErrAnchorStoreRequired)
+
+	for i := 0; i < 2; i++ {
+		// a fresh client per deploy
+		c = newFakeChannelClient(t, f)
+		store, err := disc.NewFileAnchorStore(filepath.Join(dir, "store.json"))
+		is.NoErr(err)
+		c.SetAnchorStore(store)
+		is.NoErr(c.ReloadAnchorDefinitions(path))
+	}
+	is.Equal(len(f.contents()), 2)
+	is.Equal(f.requestCount("POST /channels/channel/messages"), 2) // not posted again after the restart
+}
+
+func TestAnchorDefinitionsValidation(t *testing.T) {
+	is := is.New(t)
+	_, err := disc.ParseAnchorDefinitions([]byte(`{"anchors": [{"name": "a", "messages": [{"buttons": [{"style": "link"}]}]}]}`), "json")
+	is.True(err != nil)
+	is.True(len(err.(interface{ Unwrap() []error }).Unwrap()) == 3) // channel_id, content and url are missing
+
+	_, err = disc.ParseAnchorDefinitions([]byte(`{"anchors": [], "unknown": true}`), "json")
+	is.True(err != nil)
+
+	defs, err := disc.ParseAnchorDefinitions([]byte(`{"anchors": [{"name": "a", "channel_id": "c", "messages": [{"content": "a"}], "options": {"max_allowed_messages": 2}}]}`), "json")
+	is.True(err != nil) // keyed anchors ignore it
+	is.Equal(defs, nil)
+
+	_, err = disc.ParseAnchorDefinitions([]byte(`{"anchors": [{"name": "a", "channel_id": "c", "messages": [{"content": "a"}], "options": {"force_clear": true}}]}`), "json")
+	is.True(err != nil) // enforcing it would delete and repost the anchor
+
+	_, err = disc.ParseAnchorDefinitions([]byte(`{"anchors": [
+		{"name": "a", "channel_id": "c", "messages": [{"content": "a"}], "options": {"key": "rules"}},
+		{"name": "rules", "channel_id": "c", "messages": [{"content": "b"}]},
+		{"name": "b", "channel_id": "other", "messages": [{"content": "b"}], "options": {"key": "rules"}}
+	]}`), "json")
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), `key "rules" is already used by "a"`))
+	is.Equal(len(err.(interface{ Unwrap() []error }).Unwrap()), 1) // the key is free in the other channel
+
+	defs, err = disc.ParseAnchorDefinitions([]byte(`{"anchors": [{"name": "a", "channel_id": "c", "messages": [{"content": "{{role \"Admins\"}}"}]}]}`), "json")
+	is.NoErr(err)
+	_, err = defs.Anchors[0].Render(&disc.AnchorTemplateData{})
+	is.True(err != nil) // unknown role
+}
@@ -0,0 +1,97 @@
+package disc
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func anchorEmbeds(msg *discordgo.MessageSend) []*discordgo.MessageEmbed {
+	if msg.Embed != nil {
+		return append([]*discordgo.MessageEmbed{msg.Embed}, msg.Embeds...)
+	}
+	return msg.Embeds
+}
+
+// anchorMessageEqual reports whether the message already shows what the anchor wants to send.
+func anchorMessageEqual(m *discordgo.Message, msg *discordgo.MessageSend) bool {
+	if m.Content != msg.Content {
+		return false
+	}
+	embeds := anchorEmbeds(msg)
+	if len(m.Embeds) != len(embeds) {
+		return false
+	}
+	for i := range embeds {
+		if !anchorEmbedEqual(m.Embeds[i], embeds[i]) {
+			return false
+		}
+	}
+	return anchorComponentsEqual(m.Components, msg.Components)
+}
+
+func anchorEmbedEqual(a *discordgo.MessageEmbed, b *discordgo.MessageEmbed) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.URL != b.URL || a.Title != b.Title || a.Description != b.Description || a.Color != b.Color {
+		return false
+	}
+	if !anchorTimestampEqual(a.Timestamp, b.Timestamp) {
+		return false
+	}
+	if (a.Footer == nil) != (b.Footer == nil) || a.Footer != nil && (a.Footer.Text != b.Footer.Text || a.Footer.IconURL != b.Footer.IconURL) {
+		return false
+	}
+	if (a.Image == nil) != (b.Image == nil) || a.Image != nil && a.Image.URL != b.Image.URL {
+		return false
+	}
+	if (a.Thumbnail == nil) != (b.Thumbnail == nil) || a.Thumbnail != nil && a.Thumbnail.URL != b.Thumbnail.URL {
+		return false
+	}
+	if (a.Author == nil) != (b.Author == nil) || a.Author != nil && (a.Author.Name != b.Author.Name || a.Author.URL != b.Author.URL || a.Author.IconURL != b.Author.IconURL) {
+		return false
+	}
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Value != b.Fields[i].Value || a.Fields[i].Inline != b.Fields[i].Inline {
+			return false
+		}
+	}
+	return true
+}
+
+func anchorTimestampEqual(a string, b string) bool {
+	if a == b {
+		return true
+	}
+	at, err := time.Parse(time.RFC3339, a)
+	if err != nil {
+		return false
+	}
+	bt, err := time.Parse(time.RFC3339, b)
+	if err != nil {
+		return false
+	}
+	return at.Equal(bt)
+}
+
+// anchorComponentsEqual compares the components by their JSON, which is the same for the values we send
+// and the pointers discordgo unmarshals.
+func anchorComponentsEqual(a []discordgo.MessageComponent, b []discordgo.MessageComponent) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(aj) == string(bj)
+}
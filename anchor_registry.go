@@ -0,0 +1,228 @@
+package disc
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// anchorDeleteTTL is how long the IDs of messages deleted by anchors are remembered to ignore their delete
+// events.
+const anchorDeleteTTL = time.Minute
+
+// ErrAnchorForceClearRegistered is returned when registering an anchor with ForceClearAnchorOpt, enforcing it
+// would delete and repost the anchor on every run.
+var ErrAnchorForceClearRegistered = errors.New("registered anchors cannot use ForceClearAnchorOpt")
+
+type AnchorErrHandler func(channelID string, err error)
+
+type registeredAnchor struct {
+	channelID string
+	msgs      []*discordgo.MessageSend
+	opts      *AnchorOpts
+	// mu guards the timer, it is not held while reconciling so stopping and re-arming never wait on the
+	// network.
+	timer *time.Timer
+	mu    sync.Mutex
+	// reconcile serializes the reconciliations of the anchor.
+	reconcile sync.Mutex
+}
+
+// anchorDeleteSet remembers the messages deleted by anchors for a while.
+type anchorDeleteSet struct {
+	mu  sync.Mutex
+	ids map[string]time.Time
+}
+
+func newAnchorDeleteSet() *anchorDeleteSet {
+	return &anchorDeleteSet{
+		ids: map[string]time.Time{},
+	}
+}
+
+func (s *anchorDeleteSet) add(msgID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, expires := range s.ids {
+		if now.After(expires) {
+			delete(s.ids, id)
+		}
+	}
+	s.ids[msgID] = now.Add(anchorDeleteTTL)
+}
+
+// take forgets the messages and reports whether every one of them was deleted by an anchor.
+func (s *anchorDeleteSet) take(msgIDs ...string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	own := len(msgIDs) > 0
+	now := time.Now()
+	for _, msgID := range msgIDs {
+		expires, ok := s.ids[msgID]
+		if !ok || now.After(expires) {
+			own = false
+		}
+		delete(s.ids, msgID)
+	}
+	return own
+}
+
+// RegisterAnchor anchors the message and keeps enforcing it once HandleAnchors was called.
+func (c *Client) RegisterAnchor(channelID string, msg *discordgo.MessageSend, opts ...AnchorOptFunc) (err error) {
+	o := DefaultAnchorOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+	return c.registerAnchor(channelID, []*discordgo.MessageSend{msg}, o)
+}
+
+// RegisterAnchors anchors the messages and keeps enforcing them once HandleAnchors was called.
+func (c *Client) RegisterAnchors(channelID string, msgs []*discordgo.MessageSend, opts ...AnchorOptFunc) (err error) {
+	o := DefaultAnchorOpts()
+	o.MaxAllowedMessages = len(msgs)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return c.registerAnchor(channelID, msgs, o)
+}
+
+func (c *Client) registerAnchor(channelID string, msgs []*discordgo.MessageSend, o *AnchorOpts) (err error) {
+	if o.ForceClear {
+		return ErrAnchorForceClearRegistered
+	}
+	a := &registeredAnchor{
+		channelID: channelID,
+		msgs:      msgs,
+		opts:      o,
+	}
+	if old, ok := c.anchors.Get(a.registryKey()); ok {
+		old.stop()
+	}
+	c.anchors.Set(a.registryKey(), a)
+	a.reconcile.Lock()
+	defer a.reconcile.Unlock()
+	return c.anchor(channelID, a.msgs, a.opts)
+}
+
+// UnregisterAnchors stops enforcing every anchor registered for the channels.
+func (c *Client) UnregisterAnchors(channelIDs ...string) {
+	for _, channelID := range channelIDs {
+		for _, a := range c.registeredAnchors(channelID) {
+			a.stop()
+			c.anchors.Delete(a.registryKey())
+		}
+	}
+}
+
+// UnregisterKeyedAnchor stops enforcing the anchor registered with KeyAnchorOpt(key) for the channel.
+func (c *Client) UnregisterKeyedAnchor(channelID string, key string) {
+	k := anchorRegistryKey(channelID, key)
+	if a, ok := c.anchors.Get(k); ok {
+		a.stop()
+	}
+	c.anchors.Delete(k)
+}
+
+func (c *Client) RegisteredAnchorChannels() (channelIDs []string) {
+	c.anchors.ForEach(func(_ string, a *registeredAnchor) {
+		if !slices.Contains(channelIDs, a.channelID) {
+			channelIDs = append(channelIDs, a.channelID)
+		}
+	})
+	return channelIDs
+}
+
+func (c *Client) registeredAnchors(channelID string) (anchors []*registeredAnchor) {
+	c.anchors.ForEach(func(_ string, a *registeredAnchor) {
+		if a.channelID == channelID {
+			anchors = append(anchors, a)
+		}
+	})
+	return anchors
+}
+
+func (c *Client) SetAnchorErrHandler(handler AnchorErrHandler) {
+	c.anchorErrHandler = handler
+}
+
+// HandleAnchors adds the handlers enforcing the registered anchors when messages are sent to or deleted
+// from their channels. If you call this function multiple times it will spawn duplicate handlers.
+func (c *Client) HandleAnchors() {
+	c.sess.AddHandler(func(s *discordgo.Session, m *discordgo.MessageCreate) {
+		if m.Author != nil && s.State.User != nil && m.Author.ID == s.State.User.ID {
+			return
+		}
+		c.EnforceAnchor(m.ChannelID)
+	})
+	c.sess.AddHandler(func(s *discordgo.Session, m *discordgo.MessageDelete) {
+		c.EnforceAnchorDeleted(m.ChannelID, m.ID)
+	})
+	c.sess.AddHandler(func(s *discordgo.Session, m *discordgo.MessageDeleteBulk) {
+		c.EnforceAnchorDeleted(m.ChannelID, m.Messages...)
+	})
+}
+
+// EnforceAnchorDeleted is EnforceAnchor for messages deleted from the channel, deletes made by the anchors
+// themselves in the last minute are ignored.
+func (c *Client) EnforceAnchorDeleted(channelID string, msgIDs ...string) {
+	if c.anchorDeletes.take(msgIDs...) {
+		return
+	}
+	c.EnforceAnchor(channelID)
+}
+
+// EnforceAnchor reconciles the anchors registered for the channel once their debounce passed without
+// another call.
+func (c *Client) EnforceAnchor(channelID string) {
+	for _, a := range c.registeredAnchors(channelID) {
+		c.enforceAnchor(a)
+	}
+}
+
+func (c *Client) enforceAnchor(a *registeredAnchor) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.timer != nil {
+		a.timer.Reset(a.opts.Debounce)
+		return
+	}
+	a.timer = time.AfterFunc(a.opts.Debounce, func() {
+		a.reconcile.Lock()
+		defer a.reconcile.Unlock()
+		if registered, ok := c.anchors.Get(a.registryKey()); !ok || registered != a {
+			return
+		}
+		err := c.anchor(a.channelID, a.msgs, a.opts)
+		if err != nil {
+			c.handleAnchorErr(a.channelID, err)
+		}
+	})
+}
+
+func (c *Client) handleAnchorErr(channelID string, err error) {
+	if c.anchorErrHandler != nil {
+		c.anchorErrHandler(channelID, err)
+	} else if c.handlerErrCh != nil {
+		c.handlerErrCh <- err
+	}
+}
+
+func anchorRegistryKey(channelID string, key string) string {
+	return channelID + "/" + key
+}
+
+func (a *registeredAnchor) registryKey() string {
+	return anchorRegistryKey(a.channelID, a.opts.Key)
+}
+
+func (a *registeredAnchor) stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+}
@@ -0,0 +1,145 @@
+package disc
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"sync"
+)
+
+var ErrAnchorStoreRequired = errors.New("keyed anchors require an anchor store, set one with SetAnchorStore")
+
+// AnchorStore tracks which messages belong to keyed anchors, keyed anchors refuse to run until one is set.
+// FileAnchorStore keeps the ownership across restarts, with a MemoryAnchorStore keyed anchors are posted
+// again after one.
+type AnchorStore interface {
+	// Get returns the IDs of the messages of the anchor in their channel order.
+	Get(channelID string, key string) (msgIDs []string, err error)
+	// Set replaces the IDs of the messages of the anchor, an empty slice removes the anchor.
+	Set(channelID string, key string, msgIDs []string) (err error)
+	// Channel returns the message IDs of every anchor in the channel by key.
+	Channel(channelID string) (anchors map[string][]string, err error)
+}
+
+// MemoryAnchorStore forgets which messages belong to keyed anchors when the process stops, use it for
+// tests or channels that are cleared on startup.
+type MemoryAnchorStore struct {
+	mu       sync.Mutex
+	channels map[string]map[string][]string
+}
+
+func NewMemoryAnchorStore() *MemoryAnchorStore {
+	return &MemoryAnchorStore{
+		channels: map[string]map[string][]string{},
+	}
+}
+
+func (s *MemoryAnchorStore) Get(channelID string, key string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.channels[channelID][key]), nil
+}
+
+func (s *MemoryAnchorStore) Set(channelID string, key string, msgIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(msgIDs) == 0 {
+		delete(s.channels[channelID], key)
+		if len(s.channels[channelID]) == 0 {
+			delete(s.channels, channelID)
+		}
+		return nil
+	}
+	if s.channels[channelID] == nil {
+		s.channels[channelID] = map[string][]string{}
+	}
+	s.channels[channelID][key] = slices.Clone(msgIDs)
+	return nil
+}
+
+func (s *MemoryAnchorStore) Channel(channelID string) (map[string][]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	anchors := map[string][]string{}
+	for key, msgIDs := range s.channels[channelID] {
+		anchors[key] = slices.Clone(msgIDs)
+	}
+	return anchors, nil
+}
+
+func (s *MemoryAnchorStore) snapshot() map[string]map[string][]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	channels := map[string]map[string][]string{}
+	for channelID, anchors := range s.channels {
+		channels[channelID] = map[string][]string{}
+		for key, msgIDs := range anchors {
+			channels[channelID][key] = slices.Clone(msgIDs)
+		}
+	}
+	return channels
+}
+
+// FileAnchorStore keeps the messages of keyed anchors in a JSON file, rewritten on every change.
+type FileAnchorStore struct {
+	mu   sync.Mutex
+	path string
+	mem  *MemoryAnchorStore
+}
+
+// NewFileAnchorStore loads the store from the file, a missing file is an empty store.
+func NewFileAnchorStore(path string) (*FileAnchorStore, error) {
+	s := &FileAnchorStore{
+		path: path,
+		mem:  NewMemoryAnchorStore(),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &s.mem.channels); err != nil {
+		return nil, err
+	}
+	if s.mem.channels == nil {
+		s.mem.channels = map[string]map[string][]string{}
+	}
+	return s, nil
+}
+
+func (s *FileAnchorStore) Get(channelID string, key string) ([]string, error) {
+	return s.mem.Get(channelID, key)
+}
+
+func (s *FileAnchorStore) Set(channelID string, key string, msgIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.mem.Set(channelID, key, msgIDs); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s.mem.snapshot())
+	if err != nil {
+		return err
+	}
+	// written next to the file and renamed over it so a crash never leaves it half written
+	tmpPath := s.path + ".tmp"
+	if err = os.WriteFile(tmpPath, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, s.path)
+}
+
+func (s *FileAnchorStore) Channel(channelID string) (map[string][]string, error) {
+	return s.mem.Channel(channelID)
+}
+
+func (c *Client) SetAnchorStore(store AnchorStore) {
+	c.anchorStore = store
+}
+
+func (c *Client) AnchorStore() AnchorStore {
+	return c.anchorStore
+}
@@ -1,8 +1,19 @@
 package disc_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/matryer/is"
@@ -79,3 +90,411 @@ func TestAnchorsChannelMsg(t *testing.T) {
 	err = c.Anchors(os.Getenv("CHANNEL_ID"), msgs)
 	is.NoErr(err)
 }
+
+// fakeChannel emulates the channel message endpoints of the Discord API for a single channel.
+type fakeChannel struct {
+	mu       sync.Mutex
+	id       string
+	channel  *discordgo.Channel
+	guild    *discordgo.Guild
+	msgs     []*discordgo.Message
+	lastID   int
+	requests []string
+}
+
+func newFakeChannel(id string) *fakeChannel {
+	return &fakeChannel{
+		id:      id,
+		channel: &discordgo.Channel{ID: id, Type: discordgo.ChannelTypeGuildText},
+		lastID:  1000,
+	}
+}
+
+func (f *fakeChannel) add(authorID string, content string, age time.Duration) *discordgo.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastID++
+	m := &discordgo.Message{
+		ID:        strconv.Itoa(f.lastID),
+		ChannelID: f.id,
+		Content:   content,
+		Author:    &discordgo.User{ID: authorID},
+		Timestamp: time.Now().Add(-age),
+	}
+	f.msgs = append(f.msgs, m)
+	return m
+}
+
+func (f *fakeChannel) contents() (contents []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, m := range f.msgs {
+		contents = append(contents, m.Content)
+	}
+	return contents
+}
+
+func (f *fakeChannel) requestCount(prefix string) (count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.requests {
+		if strings.HasPrefix(r, prefix) {
+			count++
+		}
+	}
+	return count
+}
+
+func (f *fakeChannel) remove(id string) bool {
+	for i, m := range f.msgs {
+		if m.ID == id {
+			f.msgs = append(f.msgs[:i], f.msgs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeChannel) roundTrip(r *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/api/v"+discordgo.APIVersion)
+	f.requests = append(f.requests, r.Method+" "+path)
+	base := "/channels/" + f.id + "/messages"
+	respond := func(status int, body any) (*http.Response, error) {
+		b := []byte{}
+		if body != nil {
+			var err error
+			if b, err = json.Marshal(body); err != nil {
+				return nil, err
+			}
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(bytes.NewReader(b)),
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Request:    r,
+		}, nil
+	}
+	switch {
+	case r.Method == http.MethodGet && path == "/channels/"+f.id:
+		return respond(http.StatusOK, f.channel)
+	case r.Method == http.MethodGet && f.guild != nil && path == "/guilds/"+f.guild.ID:
+		return respond(http.StatusOK, f.guild)
+	case r.Method == http.MethodPatch && path == "/channels/"+f.id:
+		edit := &discordgo.ChannelEdit{}
+		if err := json.NewDecoder(r.Body).Decode(edit); err != nil {
+			return nil, err
+		}
+		if edit.Archived != nil {
+			f.channel.ThreadMetadata.Archived = *edit.Archived
+		}
+		if edit.Flags != nil {
+			f.channel.Flags = *edit.Flags
+		}
+		return respond(http.StatusOK, f.channel)
+	case r.Method == http.MethodPut && strings.HasPrefix(path, "/channels/"+f.id+"/pins/"):
+		for _, m := range f.msgs {
+			if m.ID == strings.TrimPrefix(path, "/channels/"+f.id+"/pins/") {
+				m.Pinned = true
+				return respond(http.StatusNoContent, nil)
+			}
+		}
+		return respond(http.StatusNotFound, map[string]any{"code": 10008, "message": "Unknown Message"})
+	case r.Method == http.MethodPost && strings.HasSuffix(path, "/crosspost"):
+		if f.channel.Type != discordgo.ChannelTypeGuildNews {
+			return respond(http.StatusBadRequest, map[string]any{"code": 50024, "message": "Cannot execute action on this channel type"})
+		}
+		return respond(http.StatusOK, &discordgo.Message{ID: strings.TrimSuffix(strings.TrimPrefix(path, base+"/"), "/crosspost")})
+	case r.Method == http.MethodGet && path == base:
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		before, _ := strconv.Atoi(r.URL.Query().Get("before"))
+		msgs := []*discordgo.Message{}
+		for i := len(f.msgs) - 1; i >= 0 && len(msgs) < limit; i-- {
+			id, _ := strconv.Atoi(f.msgs[i].ID)
+			if before == 0 || id < before {
+				msgs = append(msgs, f.msgs[i])
+			}
+		}
+		return respond(http.StatusOK, msgs)
+	case r.Method == http.MethodPost && path == base:
+		m := &discordgo.Message{}
+		if err := json.NewDecoder(r.Body).Decode(m); err != nil {
+			return nil, err
+		}
+		f.lastID++
+		m.ID = strconv.Itoa(f.lastID)
+		m.ChannelID = f.id
+		m.Author = &discordgo.User{ID: "bot"}
+		m.Timestamp = time.Now()
+		f.msgs = append(f.msgs, m)
+		return respond(http.StatusOK, m)
+	case r.Method == http.MethodPost && path == base+"/bulk-delete":
+		body := struct {
+			Messages []string `json:"messages"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if len(body.Messages) > 100 {
+			return respond(http.StatusBadRequest, map[string]any{"code": 50035, "message": "Invalid Form Body"})
+		}
+		for _, m := range f.msgs {
+			if slices.Contains(body.Messages, m.ID) && time.Since(m.Timestamp) > time.Hour*24*14 {
+				return respond(http.StatusBadRequest, map[string]any{"code": 50034, "message": "You can only bulk delete messages that are under 14 days old."})
+			}
+		}
+		for _, id := range body.Messages {
+			f.remove(id)
+		}
+		return respond(http.StatusNoContent, nil)
+	case r.Method == http.MethodPatch && strings.HasPrefix(path, base+"/"):
+		edit := &discordgo.Message{}
+		if err := json.NewDecoder(r.Body).Decode(edit); err != nil {
+			return nil, err
+		}
+		for _, m := range f.msgs {
+			if m.ID == strings.TrimPrefix(path, base+"/") {
+				m.Content = edit.Content
+				m.Embeds = edit.Embeds
+				m.Components = edit.Components
+				return respond(http.StatusOK, m)
+			}
+		}
+		return respond(http.StatusNotFound, map[string]any{"code": 10008, "message": "Unknown Message"})
+	case r.Method == http.MethodDelete && strings.HasPrefix(path, base+"/"):
+		if f.remove(strings.TrimPrefix(path, base+"/")) {
+			return respond(http.StatusNoContent, nil)
+		}
+		return respond(http.StatusNotFound, map[string]any{"code": 10008, "message": "Unknown Message"})
+	}
+	return respond(http.StatusNotFound, map[string]any{"code": 0, "message": "404: Not Found"})
+}
+
+func newFakeChannelClient(t *testing.T, f *fakeChannel) *disc.Client {
+	c, err := disc.NewClient("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Sess().State.User = &discordgo.User{ID: "bot"}
+	c.Sess().Client = &http.Client{Transport: roundTripFunc(f.roundTrip)}
+	return c
+}
+
+func TestAnchorEditInPlace(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("channel")
+	anchor := f.add("bot", "Rules v1", time.Hour)
+	f.add("user", "hello", time.Minute)
+	c := newFakeChannelClient(t, f)
+
+	is.NoErr(c.Anchor("channel", &discordgo.MessageSend{Content: "Rules v2"}))
+	is.Equal(f.contents(), []string{"Rules v2"})
+	is.Equal(f.msgs[0].ID, anchor.ID)
+	is.Equal(f.requestCount("DELETE"), 1)
+	is.Equal(f.requestCount("POST"), 0)
+
+	is.NoErr(c.Anchor("channel", &discordgo.MessageSend{Content: "Rules v2"}))
+	is.Equal(f.requestCount("PATCH"), 1)
+
+	is.NoErr(c.Anchors("channel", []*discordgo.MessageSend{{Content: "Rules v3"}, {Content: "Verify"}}))
+	is.Equal(f.contents(), []string{"Rules v3", "Verify"})
+	is.Equal(f.msgs[0].ID, anchor.ID)
+}
+
+func TestAnchorRegistry(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("channel")
+	c := newFakeChannelClient(t, f)
+	errCh := make(chan error, 1)
+	c.SetAnchorErrHandler(func(channelID string, err error) {
+		errCh <- err
+	})
+
+	is.NoErr(c.RegisterAnchor("channel", &discordgo.MessageSend{Content: "Rules"}, disc.DebounceAnchorOpt(time.Millisecond*50)))
+	is.Equal(f.contents(), []string{"Rules"})
+	is.Equal(c.RegisteredAnchorChannels(), []string{"channel"})
+
+	f.add("user", "hello", 0)
+	gets := f.requestCount("GET")
+	c.EnforceAnchor("channel")
+	c.EnforceAnchor("channel")
+	c.EnforceAnchor("unknown")
+	time.Sleep(time.Millisecond * 200)
+	is.Equal(f.contents(), []string{"Rules"})
+	is.Equal(f.requestCount("GET"), gets+2) // a single reconciliation fetching before and after deleting
+
+	c.UnregisterAnchors("channel")
+	f.add("user", "hello", 0)
+	c.EnforceAnchor("channel")
+	time.Sleep(time.Millisecond * 100)
+	is.Equal(f.contents(), []string{"Rules", "hello"})
+	is.Equal(len(c.RegisteredAnchorChannels()), 0)
+	select {
+	case err := <-errCh:
+		t.Fatal(err)
+	default:
+	}
+}
+
+func TestAnchorRegistryDeletes(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("channel")
+	c := newFakeChannelClient(t, f)
+	debounce := disc.DebounceAnchorOpt(time.Millisecond * 50)
+	is.Equal(c.RegisterAnchor("channel", &discordgo.MessageSend{Content: "Rules"}, debounce, disc.ForceClearAnchorOpt()), disc.ErrAnchorForceClearRegistered)
+
+	is.NoErr(c.RegisterAnchor("channel", &discordgo.MessageSend{Content: "Rules"}, debounce))
+	spam := f.add("user", "spam", 0)
+	c.EnforceAnchor("channel")
+	time.Sleep(time.Millisecond * 200)
+	is.Equal(f.contents(), []string{"Rules"})
+
+	requests := f.requestCount("")
+	c.EnforceAnchorDeleted("channel", spam.ID) // the event of the anchor's own delete
+	time.Sleep(time.Millisecond * 200)
+	is.Equal(f.requestCount(""), requests)
+
+	f.mu.Lock()
+	f.remove(f.msgs[0].ID)
+	f.mu.Unlock()
+	c.EnforceAnchorDeleted("channel", "deleted by a moderator")
+	time.Sleep(time.Millisecond * 200)
+	is.Equal(f.contents(), []string{"Rules"}) // posted again
+	c.UnregisterAnchors("channel")
+}
+
+func TestAnchorLongHistory(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("channel")
+	for i := 0; i < 30; i++ {
+		f.add("user", "old", time.Hour*24*20)
+	}
+	for i := 0; i < 220; i++ {
+		f.add("user", "young", time.Hour)
+	}
+	c := newFakeChannelClient(t, f)
+
+	is.NoErr(c.Anchor("channel", &discordgo.MessageSend{Content: "Rules"}))
+	is.Equal(f.contents(), []string{"Rules"})
+	is.Equal(f.requestCount("POST /channels/channel/messages/bulk-delete"), 3) // 100, 100 and 20 young messages
+	is.Equal(f.requestCount("DELETE"), 30)                                     // old messages deleted one by one
+}
+
+func TestAnchorKeyed(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("channel")
+	f.add("bot", "Other feature", time.Hour)
+	f.add("user", "hello", time.Minute)
+	c := newFakeChannelClient(t, f)
+	c.SetAnchorStore(disc.NewMemoryAnchorStore())
+
+	rules := []*discordgo.MessageSend{{Content: "Rule 1"}, {Content: "Rule 2"}}
+	is.NoErr(c.Anchors("channel", rules, disc.KeyAnchorOpt("rules")))
+	is.NoErr(c.Anchor("channel", &discordgo.MessageSend{Content: "Verify"}, disc.KeyAnchorOpt("verify")))
+	is.Equal(f.contents(), []string{"Other feature", "Rule 1", "Rule 2", "Verify"})
+
+	rules[0] = &discordgo.MessageSend{Content: "Rule 1 v2"}
+	is.NoErr(c.Anchors("channel", rules, disc.KeyAnchorOpt("rules")))
+	is.Equal(f.contents(), []string{"Other feature", "Rule 1 v2", "Rule 2", "Verify"})
+	is.Equal(f.requestCount("PATCH"), 1)
+
+	ruleIDs, err := c.AnchorStore().Get("channel", "rules")
+	is.NoErr(err)
+	is.Equal(len(ruleIDs), 2)
+	f.mu.Lock()
+	f.remove(ruleIDs[0])
+	f.mu.Unlock()
+	is.NoErr(c.Anchors("channel", rules, disc.KeyAnchorOpt("rules")))
+	is.Equal(f.contents(), []string{"Other feature", "Rule 1 v2", "Verify", "Rule 2"}) // rule order is kept
+
+	is.NoErr(c.Anchors("channel", rules[:1], disc.KeyAnchorOpt("rules")))
+	is.Equal(f.contents(), []string{"Other feature", "Rule 1 v2", "Verify"})
+
+	is.NoErr(c.Anchor("channel", &discordgo.MessageSend{Content: "Other feature"}, disc.MaxAllowedMessagesAnchorOpt(2)))
+	is.Equal(f.contents(), []string{"Other feature", "Rule 1 v2", "Verify"}) // keyed messages are not counted
+}
+
+func TestAnchorKeyedRestart(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("channel")
+	path := filepath.Join(t.TempDir(), "anchors.json")
+	c := newFakeChannelClient(t, f)
+	is.Equal(c.Anchor("channel", &discordgo.MessageSend{Content: "Rules"}, disc.KeyAnchorOpt("rules")), disc.ErrAnchorStoreRequired)
+
+	for i := 0; i < 2; i++ {
+		// a fresh client per run, as after a restart
+		c = newFakeChannelClient(t, f)
+		store, err := disc.NewFileAnchorStore(path)
+		is.NoErr(err)
+		c.SetAnchorStore(store)
+		is.NoErr(c.Anchor("channel", &discordgo.MessageSend{Content: "Rules"}, disc.KeyAnchorOpt("rules")))
+		is.NoErr(c.Anchor("channel", &discordgo.MessageSend{Content: "Verify"}, disc.KeyAnchorOpt("verify")))
+	}
+	is.Equal(f.contents(), []string{"Rules", "Verify"})
+	is.Equal(f.requestCount("POST"), 2)
+}
+
+func TestAnchorForumPost(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("post")
+	f.channel = &discordgo.Channel{
+		ID:             "post",
+		Type:           discordgo.ChannelTypeGuildPublicThread,
+		ThreadMetadata: &discordgo.ThreadMetadata{Archived: true},
+	}
+	f.msgs = append(f.msgs, &discordgo.Message{ID: "post", ChannelID: "post", Content: "Guide v1", Author: &discordgo.User{ID: "bot"}, Timestamp: time.Now().Add(-time.Hour * 24 * 30)})
+	f.add("user", "thanks", time.Minute)
+	c := newFakeChannelClient(t, f)
+
+	is.NoErr(c.Anchor("post", &discordgo.MessageSend{Content: "Guide v2"}, disc.UnarchiveAnchorOpt(), disc.PinAnchorOpt(), disc.ForceClearAnchorOpt()))
+	is.Equal(f.contents(), []string{"Guide v2"}) // the starter message is edited, never deleted
+	is.True(!f.channel.ThreadMetadata.Archived)
+	is.True(f.channel.Flags&discordgo.ChannelFlagPinned != 0)
+}
+
+func TestAnchorPinCrosspost(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("news")
+	f.channel.Type = discordgo.ChannelTypeGuildNews
+	c := newFakeChannelClient(t, f)
+
+	is.NoErr(c.Anchor("news", &discordgo.MessageSend{Content: "Patch notes"}, disc.PinAnchorOpt(), disc.CrosspostAnchorOpt()))
+	is.Equal(f.requestCount("POST /channels/news/messages/"), 1) // crossposted once
+	is.Equal(f.requestCount("PUT /channels/news/pins/"), 1)
+	f.add("bot", "", 0).Type = discordgo.MessageTypeChannelPinnedMessage
+
+	is.NoErr(c.Anchor("news", &discordgo.MessageSend{Content: "Patch notes"}, disc.PinAnchorOpt(), disc.CrosspostAnchorOpt()))
+	is.Equal(f.contents(), []string{"Patch notes"}) // the pin notification is removed
+	is.Equal(f.requestCount("POST /channels/news/messages/"), 1)
+	is.Equal(f.requestCount("PUT /channels/news/pins/"), 1)
+}
+
+func TestAnchorPlan(t *testing.T) {
+	is := is.New(t)
+	f := newFakeChannel("channel")
+	f.add("bot", "Rules v1", time.Hour)
+	spam := f.add("user", "spam", time.Hour*24*20)
+	f.add("user", "hello", time.Minute)
+	c := newFakeChannelClient(t, f)
+
+	plan, err := c.PlanAnchors("channel", []*discordgo.MessageSend{{Content: "Rules v2"}, {Content: "Verify"}})
+	is.NoErr(err)
+	is.Equal(len(plan.Deletes), 2)
+	is.Equal(plan.Deletes[0].Author.ID, spam.Author.ID)
+	is.True(time.Since(plan.Deletes[0].Timestamp) > time.Hour*24*14)
+	is.Equal(len(plan.Edits), 1)
+	is.Equal(plan.Edits[0].Send.Content, "Rules v2")
+	is.Equal(len(plan.Sends), 1)
+	is.Equal(f.requestCount("GET"), len(f.requests)) // nothing was written
+	is.Equal(f.contents(), []string{"Rules v1", "spam", "hello"})
+
+	err = c.Anchor("channel", &discordgo.MessageSend{Content: "Rules v2"}, disc.ForceClearAnchorOpt(), disc.MaxDeletesAnchorOpt(2))
+	maxErr := &disc.AnchorMaxDeletesError{}
+	is.True(errors.As(err, &maxErr))
+	is.Equal(maxErr.Planned, 3)
+	is.Equal(f.contents(), []string{"Rules v1", "spam", "hello"})
+
+	is.NoErr(c.Anchor("channel", &discordgo.MessageSend{Content: "Rules v2"}, disc.MaxDeletesAnchorOpt(2)))
+	is.Equal(f.contents(), []string{"Rules v2"})
+}
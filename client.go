@@ -21,6 +21,11 @@ type Client struct {
 	prefixHandler             PrefixHandler
 	suffixHandler             BaseHandler
 	handlerErrCh              chan error
+	anchors                   *structures.SafeMap[string, *registeredAnchor]
+	anchorErrHandler          AnchorErrHandler
+	anchorDeletes             *anchorDeleteSet
+	anchorStore               AnchorStore
+	anchorDefinitions         *structures.SafeMap[string, appliedAnchorDefinition]
 }
 
 type ClientType string
@@ -57,6 +62,9 @@ func NewClient(token string, appID string, clientType ...ClientType) (c *Client,
 		prefixHandler:             nil,
 		suffixHandler:             nil,
 		handlerErrCh:              nil,
+		anchors:                   structures.NewSafeMap[string, *registeredAnchor](),
+		anchorDeletes:             newAnchorDeleteSet(),
+		anchorDefinitions:         structures.NewSafeMap[string, appliedAnchorDefinition](),
 	}, nil
 }
 
@@ -106,13 +114,13 @@ func (c *Client) Handle() {
 				}()
 			}
 		case discordgo.InteractionMessageComponent:
-			if handler, ok := c.msgComponentHandlers.Get(i.MessageComponentData().CustomID); ok {
+			if name, handler, ok := c.getMsgComponentHandler(i.MessageComponentData().CustomID); ok {
 				err := handler(MsgComponentHandlerData{C: c, S: s, I: i, Data: i.MessageComponentData()})
 				go func() {
 					if err == nil {
 						return
 					}
-					handlerErrCh, ok := c.msgComponentHandlerErrChs.Get(i.MessageComponentData().CustomID)
+					handlerErrCh, ok := c.msgComponentHandlerErrChs.Get(name)
 					if ok {
 						handlerErrCh <- err
 					} else if c.handlerErrCh != nil {
@@ -227,9 +235,21 @@ func (c *Client) AddMsgComponentHandlers(handlers map[string]MsgComponentHandler
 func (c *Client) RemoveMsgComponentHandlers(names ...string) {
 	for _, name := range names {
 		c.msgComponentHandlers.Delete(name)
+		c.msgComponentHandlerErrChs.Delete(name)
 	}
 }
 
+func (c *Client) getMsgComponentHandler(customID string) (name string, handler MsgComponentHandler, ok bool) {
+	if handler, ok = c.msgComponentHandlers.Get(customID); ok {
+		return customID, handler, ok
+	}
+	if name = persistentPaginatorName(customID); name != customID {
+		handler, ok = c.msgComponentHandlers.Get(name)
+		return name, handler, ok
+	}
+	return
+}
+
 func (c *Client) AddAppCmdAutoHandler(name string, handler AppCmdAutoHandler, handlerErrCh ...chan error) {
 	if len(handlerErrCh) == 1 {
 		c.appCmdAutoHandlerErrChs.Set(name, handlerErrCh[0])
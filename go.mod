@@ -13,6 +13,7 @@ require (
 	github.com/matryer/is v1.4.1
 	github.com/stevo-go-utils/structures v0.0.0-20240523040447-f3cfefa1b29e
 	github.com/tidwall/gjson v1.17.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
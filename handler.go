@@ -124,14 +124,14 @@ func (h *GroupHandler) Handle() {
 				}()
 			}
 		case discordgo.InteractionMessageComponent:
-			if handler, ok := h.msgComponentHandlers[i.MessageComponentData().CustomID]; ok {
+			if name, handler, ok := h.getMsgComponentHandler(i.MessageComponentData().CustomID); ok {
 				err := handler(MsgComponentHandlerData{C: h.c, S: s, I: i, Data: i.MessageComponentData()})
 				go func() {
 					if err == nil {
 						return
 					}
 
-					errCh, ok := h.msgComponentHandlerErrChs[i.MessageComponentData().CustomID]
+					errCh, ok := h.msgComponentHandlerErrChs[name]
 					if ok {
 						errCh <- err
 					} else if h.errCh != nil {
@@ -226,6 +226,17 @@ func (h *GroupHandler) AddMsgComponentHandler(name string, handler MsgComponentH
 	return h
 }
 
+func (h *GroupHandler) getMsgComponentHandler(customID string) (name string, handler MsgComponentHandler, ok bool) {
+	if handler, ok = h.msgComponentHandlers[customID]; ok {
+		return customID, handler, ok
+	}
+	if name = persistentPaginatorName(customID); name != customID {
+		handler, ok = h.msgComponentHandlers[name]
+		return name, handler, ok
+	}
+	return
+}
+
 func (h *GroupHandler) AddMsgComponentHandlers(handlers map[string]MsgComponentHandler, errCh ...chan error) *GroupHandler {
 	for name, handler := range handlers {
 		if len(errCh) == 1 {
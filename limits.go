@@ -0,0 +1,113 @@
+package disc
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	LimitMessageContent   = 2000
+	LimitMessageEmbeds    = 10
+	LimitEmbedTitle       = 256
+	LimitEmbedDescription = 4096
+	LimitEmbedFields      = 25
+	LimitEmbedFieldName   = 256
+	LimitEmbedFieldValue  = 1024
+	LimitEmbedFooterText  = 2048
+	LimitEmbedAuthorName  = 256
+	LimitEmbedsTotal      = 6000
+	LimitCustomID         = 100
+)
+
+type LimitError struct {
+	Path  string
+	Limit int
+	Got   int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s exceeds discord's limit of %d (got %d)", e.Path, e.Limit, e.Got)
+}
+
+// ValidateMessage checks the content and embeds of a message against Discord's limits, all violations are
+// joined into the returned error as *LimitError.
+func ValidateMessage(content string, embeds []*discordgo.MessageEmbed) error {
+	errs := []error{}
+	if got := utf8.RuneCountInString(content); got > LimitMessageContent {
+		errs = append(errs, &LimitError{Path: "content", Limit: LimitMessageContent, Got: got})
+	}
+	if err := ValidateEmbeds(embeds...); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func ValidateEmbeds(embeds ...*discordgo.MessageEmbed) error {
+	errs := []error{}
+	if len(embeds) > LimitMessageEmbeds {
+		errs = append(errs, &LimitError{Path: "embeds", Limit: LimitMessageEmbeds, Got: len(embeds)})
+	}
+	total := 0
+	for i, embed := range embeds {
+		if embed == nil {
+			continue
+		}
+		total += EmbedSize(embed)
+		if err := validateEmbed(fmt.Sprintf("embeds[%d]", i), embed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if total > LimitEmbedsTotal {
+		errs = append(errs, &LimitError{Path: "embeds", Limit: LimitEmbedsTotal, Got: total})
+	}
+	return errors.Join(errs...)
+}
+
+func validateEmbed(path string, embed *discordgo.MessageEmbed) error {
+	errs := []error{}
+	check := func(path string, s string, limit int) {
+		if got := utf8.RuneCountInString(s); got > limit {
+			errs = append(errs, &LimitError{Path: path, Limit: limit, Got: got})
+		}
+	}
+	check(path+".title", embed.Title, LimitEmbedTitle)
+	check(path+".description", embed.Description, LimitEmbedDescription)
+	if len(embed.Fields) > LimitEmbedFields {
+		errs = append(errs, &LimitError{Path: path + ".fields", Limit: LimitEmbedFields, Got: len(embed.Fields)})
+	}
+	for i, field := range embed.Fields {
+		if field == nil {
+			continue
+		}
+		check(fmt.Sprintf("%s.fields[%d].name", path, i), field.Name, LimitEmbedFieldName)
+		check(fmt.Sprintf("%s.fields[%d].value", path, i), field.Value, LimitEmbedFieldValue)
+	}
+	if embed.Footer != nil {
+		check(path+".footer.text", embed.Footer.Text, LimitEmbedFooterText)
+	}
+	if embed.Author != nil {
+		check(path+".author.name", embed.Author.Name, LimitEmbedAuthorName)
+	}
+	return errors.Join(errs...)
+}
+
+// EmbedSize returns the amount of characters of the embed that count towards the total embed limit.
+func EmbedSize(embed *discordgo.MessageEmbed) (size int) {
+	size += utf8.RuneCountInString(embed.Title)
+	size += utf8.RuneCountInString(embed.Description)
+	for _, field := range embed.Fields {
+		if field != nil {
+			size += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+		}
+	}
+	if embed.Footer != nil {
+		size += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		size += utf8.RuneCountInString(embed.Author.Name)
+	}
+	return size
+}
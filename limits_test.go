@@ -0,0 +1,31 @@
+package disc_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/matryer/is"
+	"github.com/stevo-go-utils/disc"
+)
+
+func TestValidateMessage(t *testing.T) {
+	is := is.New(t)
+	is.NoErr(disc.ValidateMessage("content", []*discordgo.MessageEmbed{{Title: "Title", Description: "Desc"}}))
+
+	fields := []*discordgo.MessageEmbedField{}
+	for i := 0; i < 26; i++ {
+		fields = append(fields, &discordgo.MessageEmbedField{Name: "Name", Value: "Value"})
+	}
+	err := disc.ValidateMessage("", []*discordgo.MessageEmbed{
+		{Title: strings.Repeat("a", 257), Fields: fields},
+		{Description: strings.Repeat("a", 4096)},
+		{Description: strings.Repeat("a", 2000)},
+	})
+	var limitErr *disc.LimitError
+	is.True(errors.As(err, &limitErr))
+	is.True(strings.Contains(err.Error(), "embeds[0].title exceeds discord's limit of 256 (got 257)"))
+	is.True(strings.Contains(err.Error(), "embeds[0].fields exceeds discord's limit of 25 (got 26)"))
+	is.True(strings.Contains(err.Error(), "embeds exceeds discord's limit of 6000"))
+}
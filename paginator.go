@@ -1,158 +1,282 @@
 package disc
 
 import (
+	"errors"
 	"math"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/google/uuid"
 )
 
-type Paginator struct {
-	page     int
-	perPage  int
-	items    []any
-	customID string
-	*PaginatorBuilder
-}
-
-type PaginatorBuilder struct {
+type TypedPaginator[T any] struct {
+	page         int
+	perPage      int
+	items        []T
+	customID     string
+	source       TypedPaginatorSource[T]
+	cache        *paginatorCache[T]
+	curItems     []T
+	hasNext      bool
+	total        int
+	totalKnown   bool
+	totalLoaded  bool
+	lastPage     int
+	query        string
+	filtered     []T
+	bounds       []int
+	ownerID      string
+	interaction  *discordgo.Interaction
+	channelID    string
+	messageID    string
+	webhookID    string
+	webhookToken string
+	idleTimer    *time.Timer
+	closed       bool
+	mu           sync.Mutex
+	stateMu      sync.Mutex
+	*TypedPaginatorBuilder[T]
+}
+
+type TypedPaginatorBuilder[T any] struct {
 	Client            *Client
 	URL               string
-	URLFunc           func(*Paginator) string
+	URLFunc           func(*TypedPaginator[T]) string
 	Type              discordgo.EmbedType
-	TypeFunc          func(*Paginator) discordgo.EmbedType
-	TitleFunc         func(*Paginator) string
+	TypeFunc          func(*TypedPaginator[T]) discordgo.EmbedType
+	TitleFunc         func(*TypedPaginator[T]) string
 	Title             string
-	DescFunc          func(*Paginator) string
+	DescFunc          func(*TypedPaginator[T]) string
 	Desc              string
 	Timestamp         time.Time
-	TimestampFunc     func(*Paginator) time.Time
+	TimestampFunc     func(*TypedPaginator[T]) time.Time
 	Footer            *discordgo.MessageEmbedFooter
-	FooterFunc        func(*Paginator) *discordgo.MessageEmbedFooter
+	FooterFunc        func(*TypedPaginator[T]) *discordgo.MessageEmbedFooter
 	Image             *discordgo.MessageEmbedImage
-	ImageFunc         func(*Paginator) *discordgo.MessageEmbedImage
+	ImageFunc         func(*TypedPaginator[T]) *discordgo.MessageEmbedImage
 	Thumbnail         *discordgo.MessageEmbedThumbnail
-	ThumbnailFunc     func(*Paginator) *discordgo.MessageEmbedThumbnail
+	ThumbnailFunc     func(*TypedPaginator[T]) *discordgo.MessageEmbedThumbnail
 	Video             *discordgo.MessageEmbedVideo
-	VideoFunc         func(*Paginator) *discordgo.MessageEmbedVideo
+	VideoFunc         func(*TypedPaginator[T]) *discordgo.MessageEmbedVideo
 	Provider          *discordgo.MessageEmbedProvider
-	ProviderFunc      func(*Paginator) *discordgo.MessageEmbedProvider
+	ProviderFunc      func(*TypedPaginator[T]) *discordgo.MessageEmbedProvider
 	Author            *discordgo.MessageEmbedAuthor
-	AuthorFunc        func(*Paginator) *discordgo.MessageEmbedAuthor
+	AuthorFunc        func(*TypedPaginator[T]) *discordgo.MessageEmbedAuthor
 	Fields            []*discordgo.MessageEmbedField
-	FieldsFunc        func(*Paginator) []*discordgo.MessageEmbedField
+	FieldsFunc        func(*TypedPaginator[T]) []*discordgo.MessageEmbedField
 	Components        []discordgo.MessageComponent
-	ComponentsFunc    func(*Paginator) []discordgo.MessageComponent
+	ComponentsFunc    func(*TypedPaginator[T]) []discordgo.MessageComponent
 	EphemeralResponse bool
-	InitialItems      []any
-	OnPage            func(*Paginator) error
+	InitialItems      []T
+	Source            TypedPaginatorSource[T]
+	SourceCacheSize   int
+	// Deprecated: use BeforeNavigate.
+	OnPage            func(*TypedPaginator[T]) error
 	OnPageErrResp     *discordgo.InteractionResponseData
-	OnPageErrRespFunc func(*Paginator, error) *discordgo.InteractionResponseData
-	OnResp            func(*Paginator) error
+	OnPageErrRespFunc func(*TypedPaginator[T], error) *discordgo.InteractionResponseData
+	// Deprecated: use BeforeRender.
+	OnResp            func(*TypedPaginator[T]) error
 	OnRespErrResp     *discordgo.InteractionResponseData
-	OnRespErrRespFunc func(*Paginator, error) *discordgo.InteractionResponseData
-}
+	OnRespErrRespFunc func(*TypedPaginator[T], error) *discordgo.InteractionResponseData
+	BeforeNavigate    func(p *TypedPaginator[T], page int) error
+	BeforeRender      func(*TypedPaginator[T]) error
+	AfterNavigate     func(*TypedPaginator[T]) error
+	ErrResp           *discordgo.InteractionResponseData
+	ErrRespFunc       func(*TypedPaginator[T], *PaginatorError) *discordgo.InteractionResponseData
+	ErrPolicy         PaginatorErrPolicy
+	IdleTimeout       time.Duration
+	OwnerLock         bool
+	OwnerID           string
+	OwnerLockResp     *discordgo.InteractionResponseData
+	Kind              string
+	Key               string
+	PageSelect        bool
+	Categories        []PaginatorCategory
+	CategoriesFunc    func(*TypedPaginator[T]) []PaginatorCategory
+	FilterFunc        func(item T, query string) bool
+	Content           string
+	ContentFunc       func(*TypedPaginator[T]) string
+	EmbedsFunc        func(*TypedPaginator[T]) []*discordgo.MessageEmbed
+	PlainText         bool
+	RenderFunc        func(*TypedPaginator[T]) *discordgo.InteractionResponseData
+	SizeFunc          func(item T) int
+	MaxPageSize       int
+}
+
+var ErrPaginatorNotSent = errors.New("paginator has not been sent as a message")
+
+type Paginator = TypedPaginator[any]
+
+type PaginatorBuilder = TypedPaginatorBuilder[any]
 
 func (c *Client) NewPaginatorBuilder() *PaginatorBuilder {
-	return &PaginatorBuilder{
+	return NewTypedPaginatorBuilder[any](c)
+}
+
+func NewTypedPaginatorBuilder[T any](c *Client) *TypedPaginatorBuilder[T] {
+	return &TypedPaginatorBuilder[T]{
 		Client: c,
 	}
 }
 
-func (b *PaginatorBuilder) Build(perPage int) (p *Paginator, msgComponentHandlers map[string]MsgComponentHandler) {
-	p = &Paginator{
-		page:             1,
-		perPage:          perPage,
-		items:            b.InitialItems,
-		customID:         uuid.New().String(),
-		PaginatorBuilder: b,
-	}
-	onPage := func(s *discordgo.Session, i *discordgo.InteractionCreate) (sent bool, err error) {
-		if p.OnPage != nil {
-			err := p.OnPage(p)
-			if err != nil {
-				if p.OnPageErrRespFunc != nil {
-					return true, s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: p.OnPageErrRespFunc(p, err),
-					})
-				}
-				if p.OnPageErrResp != nil {
-					return true, s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-						Type: discordgo.InteractionResponseChannelMessageWithSource,
-						Data: p.OnPageErrResp,
-					})
-				}
-				return true, s.InteractionRespond(i.Interaction, p.UpdateResponse())
-			}
-		}
-		return
-	}
-	return p, map[string]MsgComponentHandler{
-		p.customID + "-prev": func(data MsgComponentHandlerData) error {
-			sent, err := onPage(data.S, data.I)
-			if err != nil {
-				return err
-			}
-			if sent {
-				return nil
-			}
-			p.page--
-			return data.S.InteractionRespond(data.I.Interaction, p.UpdateResponse())
-		},
-		p.customID + "-next": func(data MsgComponentHandlerData) error {
-			sent, err := onPage(data.S, data.I)
-			if err != nil {
-				return err
-			}
-			if sent {
-				return nil
-			}
-			p.page++
-			return data.S.InteractionRespond(data.I.Interaction, p.UpdateResponse())
-		},
+func (b *TypedPaginatorBuilder[T]) Build(perPage int) (p *TypedPaginator[T], msgComponentHandlers map[string]MsgComponentHandler) {
+	p = &TypedPaginator[T]{
+		page:                  1,
+		perPage:               perPage,
+		items:                 b.InitialItems,
+		customID:              uuid.New().String(),
+		source:                b.Source,
+		cache:                 newPaginatorCache[T](b.SourceCacheSize),
+		ownerID:               b.OwnerID,
+		TypedPaginatorBuilder: b,
+	}
+	p.applyFilter()
+	if p.Kind != "" {
+		return p, map[string]MsgComponentHandler{}
 	}
+	msgComponentHandlers = map[string]MsgComponentHandler{
+		p.prevID(): p.prev,
+		p.nextID(): p.next,
+	}
+	if p.selectEnabled() {
+		msgComponentHandlers[p.selectID()] = p.selectPage
+	}
+	if p.searchEnabled() {
+		msgComponentHandlers[p.searchID()] = p.openSearch
+	}
+	return p, msgComponentHandlers
+}
+
+func (p *TypedPaginator[T]) prev(data MsgComponentHandlerData) error {
+	return p.navigate(data.S, data.I, func() int {
+		return p.page - 1
+	})
+}
+
+func (p *TypedPaginator[T]) next(data MsgComponentHandlerData) error {
+	return p.navigate(data.S, data.I, func() int {
+		return p.page + 1
+	})
 }
 
-func (p Paginator) Items() []any {
+// navigate moves to the target page and renders it while the paginator's state is locked, so concurrent
+// clicks are applied one after another. Out of range targets re-render the current page.
+func (p *TypedPaginator[T]) navigate(s *discordgo.Session, i *discordgo.InteractionCreate, target func() int) error {
+	if handled, err := p.guard(s, i); handled {
+		return err
+	}
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	page := target()
+	if !p.validPage(page) {
+		return p.respond(s, i, discordgo.InteractionResponseUpdateMessage)
+	}
+	if err := p.beforeNavigate(page); err != nil {
+		return p.fail(s, i, discordgo.InteractionResponseUpdateMessage, err)
+	}
+	prevPage := p.page
+	p.page = page
+	data, renderErr := p.renderData()
+	if renderErr != nil {
+		p.page = prevPage
+		return p.fail(s, i, discordgo.InteractionResponseUpdateMessage, renderErr)
+	}
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: data,
+	})
+	if err != nil {
+		return err
+	}
+	if err := p.afterNavigate(); err != nil {
+		return p.failAfterResponse(s, i, err)
+	}
+	return nil
+}
+
+func (p *TypedPaginator[T]) validPage(page int) bool {
+	if page < 1 {
+		return false
+	}
+	if page <= p.page {
+		return true
+	}
+	if last := p.LastPage(); last != UnknownLastPage {
+		return page <= last
+	}
+	return page == p.page+1 && p.hasNext
+}
+
+// Items returns the items without locking the paginator's state, see Query for when it may be called.
+func (p *TypedPaginator[T]) Items() []T {
 	return p.items
 }
 
-func (p *Paginator) SetItems(items []any) {
+// SetItems replaces the items, it must not be called from the paginator's hooks and render functions,
+// which already run while its state is locked.
+func (p *TypedPaginator[T]) SetItems(items []T) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
 	p.items = items
+	p.applyFilter()
 }
 
-func (p Paginator) Page() int {
+// Page returns the current page without locking the paginator's state, see Query for when it may be called.
+func (p *TypedPaginator[T]) Page() int {
 	return p.page
 }
 
-func (p Paginator) PerPage() int {
+func (p *TypedPaginator[T]) PerPage() int {
 	return p.perPage
 }
 
-func (p Paginator) LastPage() int {
-	return int(math.Ceil(float64(float64(len(p.items)) / float64(p.perPage))))
+// LastPage, HasNextPage, CurPageItems and CurPageIdxs read the state without locking it, see Query for when
+// they may be called.
+func (p *TypedPaginator[T]) LastPage() int {
+	if p.source == nil && p.autoSplit() {
+		return len(p.bounds)
+	}
+	if p.source == nil {
+		return int(math.Ceil(float64(float64(len(p.viewItems())) / float64(p.perPage))))
+	}
+	if p.totalKnown {
+		return int(math.Ceil(float64(float64(p.total) / float64(p.perPage))))
+	}
+	if p.lastPage > 0 {
+		return p.lastPage
+	}
+	return UnknownLastPage
 }
 
-func (p Paginator) CurPageItems() []any {
-	start := (p.page - 1) * p.perPage
-	if start > len(p.items) {
-		start = len(p.items)
+func (p *TypedPaginator[T]) LastPageStr() string {
+	if lastPage := p.LastPage(); lastPage != UnknownLastPage {
+		return strconv.Itoa(lastPage)
 	}
-	end := start + p.perPage
-	if end > len(p.items) {
-		end = len(p.items)
+	return "?"
+}
+
+func (p *TypedPaginator[T]) HasNextPage() bool {
+	if p.source == nil {
+		return p.page < p.LastPage()
 	}
+	return p.hasNext
+}
 
-	return p.items[start:end]
+func (p *TypedPaginator[T]) CurPageItems() []T {
+	if p.source != nil {
+		return p.curItems
+	}
+	start, end := p.pageRange(p.page)
+	return p.viewItems()[start:end]
 }
 
-func (p Paginator) CurPageIdxs() (idxs []int) {
-	start := (p.page - 1) * p.perPage
-	end := start + p.perPage
-	if end > len(p.items) {
-		end = len(p.items)
+func (p *TypedPaginator[T]) CurPageIdxs() (idxs []int) {
+	start, end := p.pageRange(p.page)
+	if p.source != nil {
+		start = (p.page - 1) * p.perPage
+		end = start + len(p.curItems)
 	}
 	for i := start; i < end; i++ {
 		idxs = append(idxs, i)
@@ -160,109 +284,56 @@ func (p Paginator) CurPageIdxs() (idxs []int) {
 	return
 }
 
-func (p *Paginator) Response() *discordgo.InteractionResponse {
-	if p.OnResp != nil {
-		err := p.OnResp(p)
-		if err != nil {
-			if p.OnRespErrRespFunc != nil {
-				return &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: p.OnRespErrRespFunc(p, err),
-				}
-			}
-			if p.OnRespErrResp != nil {
-				return &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: p.OnRespErrResp,
-				}
-			}
-			return p.UpdateResponse()
-		}
-	}
-	return &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{
-				{
-					URL:         p.getURL(),
-					Type:        p.getType(),
-					Title:       p.getTitle(),
-					Description: p.getDesc(),
-					Timestamp:   p.getTimestamp(),
-					Footer:      p.getFooter(),
-					Image:       p.getImage(),
-					Thumbnail:   p.getThumbnail(),
-					Video:       p.getVideo(),
-					Provider:    p.getProvider(),
-					Author:      p.getAuthor(),
-					Fields:      p.getFields(),
-				},
-			},
-			Components: p.getComponents(),
-			Flags:      p.getFlags(),
-		},
-	}
+func (p *TypedPaginator[T]) Response() *discordgo.InteractionResponse {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	return p.response()
+}
+
+func (p *TypedPaginator[T]) UpdateResponse() *discordgo.InteractionResponse {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	return p.updateResponse()
+}
+
+func (p *TypedPaginator[T]) response() *discordgo.InteractionResponse {
+	return p.responseOf(discordgo.InteractionResponseChannelMessageWithSource)
+}
+
+func (p *TypedPaginator[T]) updateResponse() *discordgo.InteractionResponse {
+	return p.responseOf(discordgo.InteractionResponseUpdateMessage)
 }
 
-func (p *Paginator) UpdateResponse() *discordgo.InteractionResponse {
-	if p.OnResp != nil {
-		err := p.OnResp(p)
-		if err != nil {
-			if p.OnRespErrRespFunc != nil {
-				return &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: p.OnRespErrRespFunc(p, err),
-				}
-			}
-			if p.OnRespErrResp != nil {
-				return &discordgo.InteractionResponse{
-					Type: discordgo.InteractionResponseChannelMessageWithSource,
-					Data: p.OnRespErrResp,
-				}
-			}
-			return p.UpdateResponse()
+// responseOf renders the current page, falling back to the error response when rendering fails.
+func (p *TypedPaginator[T]) responseOf(respType discordgo.InteractionResponseType) *discordgo.InteractionResponse {
+	data, err := p.renderData()
+	if err != nil {
+		data = p.errRespData(err, p.ErrPolicy != PaginatorErrPolicyEphemeral)
+		if p.ErrPolicy == PaginatorErrPolicyEphemeral {
+			data.Flags |= discordgo.MessageFlagsEphemeral
 		}
 	}
 	return &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseUpdateMessage,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{
-				{
-					URL:         p.getURL(),
-					Type:        p.getType(),
-					Title:       p.getTitle(),
-					Description: p.getDesc(),
-					Timestamp:   p.getTimestamp(),
-					Footer:      p.getFooter(),
-					Image:       p.getImage(),
-					Thumbnail:   p.getThumbnail(),
-					Video:       p.getVideo(),
-					Provider:    p.getProvider(),
-					Author:      p.getAuthor(),
-					Fields:      p.getFields(),
-				},
-			},
-			Components: p.getComponents(),
-			Flags:      p.getFlags(),
-		},
+		Type: respType,
+		Data: data,
 	}
 }
 
-func (p Paginator) getURL() string {
+func (p *TypedPaginator[T]) getURL() string {
 	if p.URLFunc != nil {
-		return p.URLFunc(&p)
+		return p.URLFunc(p)
 	}
 	return p.URL
 }
 
-func (p *Paginator) getType() discordgo.EmbedType {
+func (p *TypedPaginator[T]) getType() discordgo.EmbedType {
 	if p.TypeFunc != nil {
 		return p.TypeFunc(p)
 	}
 	return p.Type
 }
 
-func (p *Paginator) getTimestamp() string {
+func (p *TypedPaginator[T]) getTimestamp() string {
 
 	if p.TimestampFunc != nil {
 		if p.TimestampFunc(p).IsZero() {
@@ -276,267 +347,413 @@ func (p *Paginator) getTimestamp() string {
 	return p.Timestamp.UTC().Format("2006-01-02T15:04:05.999Z")
 }
 
-func (p *Paginator) getFooter() *discordgo.MessageEmbedFooter {
+func (p *TypedPaginator[T]) getFooter() *discordgo.MessageEmbedFooter {
+	footer := p.Footer
 	if p.FooterFunc != nil {
-		return p.FooterFunc(p)
+		footer = p.FooterFunc(p)
 	}
-	return p.Footer
+	return p.withQueryFooter(footer)
 }
 
-func (p *Paginator) getImage() *discordgo.MessageEmbedImage {
+func (p *TypedPaginator[T]) getImage() *discordgo.MessageEmbedImage {
 	if p.ImageFunc != nil {
 		return p.ImageFunc(p)
 	}
 	return p.Image
 }
 
-func (p *Paginator) getThumbnail() *discordgo.MessageEmbedThumbnail {
+func (p *TypedPaginator[T]) getThumbnail() *discordgo.MessageEmbedThumbnail {
 	if p.ThumbnailFunc != nil {
 		return p.ThumbnailFunc(p)
 	}
 	return p.Thumbnail
 }
 
-func (p *Paginator) getVideo() *discordgo.MessageEmbedVideo {
+func (p *TypedPaginator[T]) getVideo() *discordgo.MessageEmbedVideo {
 	if p.VideoFunc != nil {
 		return p.VideoFunc(p)
 	}
 	return p.Video
 }
 
-func (p *Paginator) getProvider() *discordgo.MessageEmbedProvider {
+func (p *TypedPaginator[T]) getProvider() *discordgo.MessageEmbedProvider {
 	if p.ProviderFunc != nil {
 		return p.ProviderFunc(p)
 	}
 	return p.Provider
 }
 
-func (p *Paginator) getAuthor() *discordgo.MessageEmbedAuthor {
+func (p *TypedPaginator[T]) getAuthor() *discordgo.MessageEmbedAuthor {
 	if p.AuthorFunc != nil {
 		return p.AuthorFunc(p)
 	}
 	return p.Author
 }
 
-func (p *Paginator) getFields() []*discordgo.MessageEmbedField {
+func (p *TypedPaginator[T]) getFields() []*discordgo.MessageEmbedField {
 	if p.FieldsFunc != nil {
 		return p.FieldsFunc(p)
 	}
 	return p.Fields
 }
 
-func (p *Paginator) getTitle() string {
+func (p *TypedPaginator[T]) getTitle() string {
 	if p.TitleFunc != nil {
 		return p.TitleFunc(p)
 	}
 	return p.Title
 }
 
-func (p *Paginator) getDesc() string {
+func (p *TypedPaginator[T]) getDesc() string {
 	if p.DescFunc != nil {
 		return p.DescFunc(p)
 	}
 	return p.Desc
 }
 
-func (p *Paginator) getComponents() []discordgo.MessageComponent {
+func (p *TypedPaginator[T]) getComponents() []discordgo.MessageComponent {
+	navComponents := []discordgo.MessageComponent{
+		discordgo.Button{
+			Label:    "Prev",
+			Style:    discordgo.PrimaryButton,
+			CustomID: p.prevID(),
+			Disabled: p.page == 1 || p.isClosed(),
+		},
+		discordgo.Button{
+			Label:    "Next",
+			Style:    discordgo.PrimaryButton,
+			CustomID: p.nextID(),
+			Disabled: !p.HasNextPage() || p.isClosed(),
+		},
+	}
+	if p.searchEnabled() {
+		navComponents = append(navComponents, discordgo.Button{
+			Label:    "Search",
+			Style:    discordgo.SecondaryButton,
+			CustomID: p.searchID(),
+			Disabled: p.isClosed(),
+		})
+	}
 	baseComponents := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				discordgo.Button{
-					Label:    "Prev",
-					Style:    discordgo.PrimaryButton,
-					CustomID: p.customID + "-prev",
-					Disabled: p.page == 1,
-				},
-				discordgo.Button{
-					Label:    "Next",
-					Style:    discordgo.PrimaryButton,
-					CustomID: p.customID + "-next",
-					Disabled: p.page == p.LastPage() || p.LastPage() == 0,
-				},
-			},
+			Components: navComponents,
 		},
 	}
+	if selectMenu, ok := p.getSelectMenu(); ok {
+		baseComponents = append([]discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{selectMenu},
+			},
+		}, baseComponents...)
+	}
 	if p.ComponentsFunc != nil {
 		return append(p.ComponentsFunc(p), baseComponents...)
 	}
 	return append(p.Components, baseComponents...)
 }
 
-func (p *Paginator) getFlags() discordgo.MessageFlags {
+func (p *TypedPaginator[T]) getFlags() discordgo.MessageFlags {
 	if p.EphemeralResponse {
 		return 1 << 6
 	}
 	return 0
 }
 
-func (p *PaginatorBuilder) SetTitleFunc(titleFunc func(*Paginator) string) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetTitleFunc(titleFunc func(*TypedPaginator[T]) string) *TypedPaginatorBuilder[T] {
 	p.TitleFunc = titleFunc
 	return p
 }
 
-func (p *PaginatorBuilder) SetTitle(title string) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetTitle(title string) *TypedPaginatorBuilder[T] {
 	p.Title = title
 	return p
 }
 
-func (p *PaginatorBuilder) SetDescFunc(descFunc func(*Paginator) string) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetDescFunc(descFunc func(*TypedPaginator[T]) string) *TypedPaginatorBuilder[T] {
 	p.DescFunc = descFunc
 	return p
 }
 
-func (p *PaginatorBuilder) SetDesc(desc string) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetDesc(desc string) *TypedPaginatorBuilder[T] {
 	p.Desc = desc
 	return p
 }
 
-func (p *PaginatorBuilder) UseEphemeralResponse() *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) UseEphemeralResponse() *TypedPaginatorBuilder[T] {
 	p.EphemeralResponse = true
 	return p
 }
 
-func (p *PaginatorBuilder) SetInitialItems(items []any) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetInitialItems(items []T) *TypedPaginatorBuilder[T] {
 	p.InitialItems = items
 	return p
 }
 
-func (p *PaginatorBuilder) SetURLFunc(urlFunc func(*Paginator) string) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetSource(source TypedPaginatorSource[T]) *TypedPaginatorBuilder[T] {
+	p.Source = source
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetSourceCacheSize(size int) *TypedPaginatorBuilder[T] {
+	p.SourceCacheSize = size
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetURLFunc(urlFunc func(*TypedPaginator[T]) string) *TypedPaginatorBuilder[T] {
 	p.URLFunc = urlFunc
 	return p
 }
 
-func (p *PaginatorBuilder) SetURL(url string) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetURL(url string) *TypedPaginatorBuilder[T] {
 	p.URL = url
 	return p
 }
 
-func (p *PaginatorBuilder) SetTypeFunc(t func(*Paginator) discordgo.EmbedType) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetTypeFunc(t func(*TypedPaginator[T]) discordgo.EmbedType) *TypedPaginatorBuilder[T] {
 	p.TypeFunc = t
 	return p
 }
 
-func (p *PaginatorBuilder) SetType(t discordgo.EmbedType) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetType(t discordgo.EmbedType) *TypedPaginatorBuilder[T] {
 	p.Type = t
 	return p
 }
 
-func (p *PaginatorBuilder) SetTimestampFunc(t func(*Paginator) time.Time) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetTimestampFunc(t func(*TypedPaginator[T]) time.Time) *TypedPaginatorBuilder[T] {
 	p.TimestampFunc = t
 	return p
 }
 
-func (p *PaginatorBuilder) SetTimestamp(t time.Time) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetTimestamp(t time.Time) *TypedPaginatorBuilder[T] {
 	p.Timestamp = t
 	return p
 }
 
-func (p *PaginatorBuilder) SetFooterFunc(f func(*Paginator) *discordgo.MessageEmbedFooter) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetFooterFunc(f func(*TypedPaginator[T]) *discordgo.MessageEmbedFooter) *TypedPaginatorBuilder[T] {
 	p.FooterFunc = f
 	return p
 }
 
-func (p *PaginatorBuilder) SetFooter(f *discordgo.MessageEmbedFooter) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetFooter(f *discordgo.MessageEmbedFooter) *TypedPaginatorBuilder[T] {
 	p.Footer = f
 	return p
 }
 
-func (p *PaginatorBuilder) SetImageFunc(i func(*Paginator) *discordgo.MessageEmbedImage) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetImageFunc(i func(*TypedPaginator[T]) *discordgo.MessageEmbedImage) *TypedPaginatorBuilder[T] {
 	p.ImageFunc = i
 	return p
 }
 
-func (p *PaginatorBuilder) SetImage(i *discordgo.MessageEmbedImage) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetImage(i *discordgo.MessageEmbedImage) *TypedPaginatorBuilder[T] {
 	p.Image = i
 	return p
 }
 
-func (p *PaginatorBuilder) SetThumbnailFunc(t func(*Paginator) *discordgo.MessageEmbedThumbnail) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetThumbnailFunc(t func(*TypedPaginator[T]) *discordgo.MessageEmbedThumbnail) *TypedPaginatorBuilder[T] {
 	p.ThumbnailFunc = t
 	return p
 }
 
-func (p *PaginatorBuilder) SetThumbnail(t *discordgo.MessageEmbedThumbnail) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetThumbnail(t *discordgo.MessageEmbedThumbnail) *TypedPaginatorBuilder[T] {
 	p.Thumbnail = t
 	return p
 }
 
-func (p *PaginatorBuilder) SetVideoFunc(v func(*Paginator) *discordgo.MessageEmbedVideo) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetVideoFunc(v func(*TypedPaginator[T]) *discordgo.MessageEmbedVideo) *TypedPaginatorBuilder[T] {
 	p.VideoFunc = v
 	return p
 }
 
-func (p *PaginatorBuilder) SetVideo(v *discordgo.MessageEmbedVideo) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetVideo(v *discordgo.MessageEmbedVideo) *TypedPaginatorBuilder[T] {
 	p.Video = v
 	return p
 }
 
-func (p *PaginatorBuilder) SetProviderFunc(pr func(*Paginator) *discordgo.MessageEmbedProvider) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetProviderFunc(pr func(*TypedPaginator[T]) *discordgo.MessageEmbedProvider) *TypedPaginatorBuilder[T] {
 	p.ProviderFunc = pr
 	return p
 }
 
-func (p *PaginatorBuilder) SetProvider(pr *discordgo.MessageEmbedProvider) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetProvider(pr *discordgo.MessageEmbedProvider) *TypedPaginatorBuilder[T] {
 	p.Provider = pr
 	return p
 }
 
-func (p *PaginatorBuilder) SetAuthorFunc(a func(*Paginator) *discordgo.MessageEmbedAuthor) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetAuthorFunc(a func(*TypedPaginator[T]) *discordgo.MessageEmbedAuthor) *TypedPaginatorBuilder[T] {
 	p.AuthorFunc = a
 	return p
 }
 
-func (p *PaginatorBuilder) SetAuthor(a *discordgo.MessageEmbedAuthor) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetAuthor(a *discordgo.MessageEmbedAuthor) *TypedPaginatorBuilder[T] {
 	p.Author = a
 	return p
 }
 
-func (p *PaginatorBuilder) SetFieldsFunc(f func(*Paginator) []*discordgo.MessageEmbedField) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetFieldsFunc(f func(*TypedPaginator[T]) []*discordgo.MessageEmbedField) *TypedPaginatorBuilder[T] {
 	p.FieldsFunc = f
 	return p
 }
 
-func (p *PaginatorBuilder) SetFields(f []*discordgo.MessageEmbedField) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetFields(f []*discordgo.MessageEmbedField) *TypedPaginatorBuilder[T] {
 	p.Fields = f
 	return p
 }
 
-func (p *PaginatorBuilder) SetComponentsFunc(c func(*Paginator) []discordgo.MessageComponent) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetComponentsFunc(c func(*TypedPaginator[T]) []discordgo.MessageComponent) *TypedPaginatorBuilder[T] {
 	p.ComponentsFunc = c
 	return p
 }
 
-func (p *PaginatorBuilder) SetComponents(c []discordgo.MessageComponent) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetComponents(c []discordgo.MessageComponent) *TypedPaginatorBuilder[T] {
 	p.Components = c
 	return p
 }
 
-func (p *PaginatorBuilder) SetOnPage(onPage func(*Paginator) error) *PaginatorBuilder {
+// SetIdleTimeout closes the paginator once nobody used it for the duration. The timer starts when the paginator
+// is sent with Respond, Send or Edit, or on its first interaction.
+func (p *TypedPaginatorBuilder[T]) SetIdleTimeout(idleTimeout time.Duration) *TypedPaginatorBuilder[T] {
+	p.IdleTimeout = idleTimeout
+	return p
+}
+
+// UseOwnerLock locks the paginator to the user it was sent to with Respond, or to the first user to interact
+// with it.
+func (p *TypedPaginatorBuilder[T]) UseOwnerLock() *TypedPaginatorBuilder[T] {
+	p.OwnerLock = true
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetOwnerID(ownerID string) *TypedPaginatorBuilder[T] {
+	p.OwnerLock = true
+	p.OwnerID = ownerID
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetOwnerLockResp(ownerLockResp *discordgo.InteractionResponseData) *TypedPaginatorBuilder[T] {
+	p.OwnerLockResp = ownerLockResp
+	return p
+}
+
+// SetPersistent encodes the kind and key in the custom IDs of the paginator's components, rendering it fails
+// with a *LimitError when they exceed Discord's limit of 100 characters.
+func (p *TypedPaginatorBuilder[T]) SetPersistent(kind string, key string) *TypedPaginatorBuilder[T] {
+	p.Kind = kind
+	p.Key = key
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) UsePageSelect() *TypedPaginatorBuilder[T] {
+	p.PageSelect = true
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetCategories(categories []PaginatorCategory) *TypedPaginatorBuilder[T] {
+	p.Categories = categories
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetCategoriesFunc(categoriesFunc func(*TypedPaginator[T]) []PaginatorCategory) *TypedPaginatorBuilder[T] {
+	p.CategoriesFunc = categoriesFunc
+	return p
+}
+
+// SetFilterFunc enables searching the items, paginators with a source search through a
+// TypedPaginatorQuerySource instead and ignore it. The search modal is handled through the paginator's client
+// once the search button opened it.
+func (p *TypedPaginatorBuilder[T]) SetFilterFunc(filterFunc func(item T, query string) bool) *TypedPaginatorBuilder[T] {
+	p.FilterFunc = filterFunc
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetContentFunc(contentFunc func(*TypedPaginator[T]) string) *TypedPaginatorBuilder[T] {
+	p.ContentFunc = contentFunc
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetContent(content string) *TypedPaginatorBuilder[T] {
+	p.Content = content
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetEmbedsFunc(embedsFunc func(*TypedPaginator[T]) []*discordgo.MessageEmbed) *TypedPaginatorBuilder[T] {
+	p.EmbedsFunc = embedsFunc
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) UsePlainText() *TypedPaginatorBuilder[T] {
+	p.PlainText = true
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetRenderFunc(renderFunc func(*TypedPaginator[T]) *discordgo.InteractionResponseData) *TypedPaginatorBuilder[T] {
+	p.RenderFunc = renderFunc
+	return p
+}
+
+// SetAutoSplit splits the items into pages by their size instead of a fixed amount, a page holds items
+// until their summed size would exceed maxPageSize and never more than perPage items.
+func (p *TypedPaginatorBuilder[T]) SetAutoSplit(sizeFunc func(item T) int, maxPageSize int) *TypedPaginatorBuilder[T] {
+	p.SizeFunc = sizeFunc
+	p.MaxPageSize = maxPageSize
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetBeforeNavigate(beforeNavigate func(p *TypedPaginator[T], page int) error) *TypedPaginatorBuilder[T] {
+	p.BeforeNavigate = beforeNavigate
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetBeforeRender(beforeRender func(*TypedPaginator[T]) error) *TypedPaginatorBuilder[T] {
+	p.BeforeRender = beforeRender
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetAfterNavigate(afterNavigate func(*TypedPaginator[T]) error) *TypedPaginatorBuilder[T] {
+	p.AfterNavigate = afterNavigate
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetErrResp(errResp *discordgo.InteractionResponseData) *TypedPaginatorBuilder[T] {
+	p.ErrResp = errResp
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetErrRespFunc(errRespFunc func(*TypedPaginator[T], *PaginatorError) *discordgo.InteractionResponseData) *TypedPaginatorBuilder[T] {
+	p.ErrRespFunc = errRespFunc
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetErrPolicy(errPolicy PaginatorErrPolicy) *TypedPaginatorBuilder[T] {
+	p.ErrPolicy = errPolicy
+	return p
+}
+
+func (p *TypedPaginatorBuilder[T]) SetOnPage(onPage func(*TypedPaginator[T]) error) *TypedPaginatorBuilder[T] {
 	p.OnPage = onPage
 	return p
 }
 
-func (p *PaginatorBuilder) SetOnPageErrResp(onPageErrResp *discordgo.InteractionResponseData) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetOnPageErrResp(onPageErrResp *discordgo.InteractionResponseData) *TypedPaginatorBuilder[T] {
 	p.OnPageErrResp = onPageErrResp
 	return p
 }
 
-func (p *PaginatorBuilder) SetOnPageErrRespFunc(onPageErrResp func(*Paginator, error) *discordgo.InteractionResponseData) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetOnPageErrRespFunc(onPageErrResp func(*TypedPaginator[T], error) *discordgo.InteractionResponseData) *TypedPaginatorBuilder[T] {
 	p.OnPageErrRespFunc = onPageErrResp
 	return p
 }
 
-func (p *PaginatorBuilder) SetOnResp(onResp func(*Paginator) error) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetOnResp(onResp func(*TypedPaginator[T]) error) *TypedPaginatorBuilder[T] {
 	p.OnResp = onResp
 	return p
 }
 
-func (p *PaginatorBuilder) SetOnRespErrResp(onRespErrResp *discordgo.InteractionResponseData) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetOnRespErrResp(onRespErrResp *discordgo.InteractionResponseData) *TypedPaginatorBuilder[T] {
 	p.OnRespErrResp = onRespErrResp
 	return p
 }
 
-func (p *PaginatorBuilder) SetOnRespErrRespFunc(onRespErrResp func(*Paginator, error) *discordgo.InteractionResponseData) *PaginatorBuilder {
+func (p *TypedPaginatorBuilder[T]) SetOnRespErrRespFunc(onRespErrResp func(*TypedPaginator[T], error) *discordgo.InteractionResponseData) *TypedPaginatorBuilder[T] {
 	p.OnRespErrRespFunc = onRespErrResp
 	return p
 }
@@ -0,0 +1,204 @@
+package disc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+type PaginatorStage string
+
+const (
+	PaginatorStageLoad           PaginatorStage = "load"
+	PaginatorStageBeforeNavigate PaginatorStage = "before-navigate"
+	PaginatorStageRender         PaginatorStage = "render"
+	PaginatorStageValidate       PaginatorStage = "validate"
+	PaginatorStageAfterNavigate  PaginatorStage = "after-navigate"
+)
+
+type PaginatorErrPolicy int
+
+const (
+	// PaginatorErrPolicyUpdate replaces the paginator's message with the error response, keeping its navigation.
+	PaginatorErrPolicyUpdate PaginatorErrPolicy = iota
+	// PaginatorErrPolicyEphemeral keeps the current page and sends the error response as an ephemeral message.
+	PaginatorErrPolicyEphemeral
+	// PaginatorErrPolicyPropagate keeps the current page and returns the error to the client's error channels.
+	PaginatorErrPolicyPropagate
+)
+
+type PaginatorError struct {
+	Stage PaginatorStage
+	Page  int
+	Err   error
+}
+
+func (e *PaginatorError) Error() string {
+	return fmt.Sprintf("paginator %s failed on page %d: %v", e.Stage, e.Page, e.Err)
+}
+
+func (e *PaginatorError) Unwrap() error {
+	return e.Err
+}
+
+// Render renders the current page, a failing load, BeforeRender hook or a page exceeding Discord's limits
+// is returned as a *PaginatorError.
+func (p *TypedPaginator[T]) Render() (data *discordgo.InteractionResponseData, err error) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	data, perr := p.renderData()
+	if perr != nil {
+		return nil, perr
+	}
+	return data, nil
+}
+
+func (p *TypedPaginator[T]) renderData() (*discordgo.InteractionResponseData, *PaginatorError) {
+	if err := p.load(); err != nil {
+		return nil, p.newErr(PaginatorStageLoad, err)
+	}
+	if p.OnResp != nil {
+		if err := p.OnResp(p); err != nil {
+			return nil, p.newErr(PaginatorStageRender, err)
+		}
+	}
+	if p.BeforeRender != nil {
+		if err := p.BeforeRender(p); err != nil {
+			return nil, p.newErr(PaginatorStageRender, err)
+		}
+	}
+	data := p.render()
+	if err := errors.Join(ValidateMessage(data.Content, data.Embeds), p.validateCustomIDs()); err != nil {
+		return nil, p.newErr(PaginatorStageValidate, err)
+	}
+	return data, nil
+}
+
+func (p *TypedPaginator[T]) beforeNavigate(page int) *PaginatorError {
+	if p.OnPage != nil {
+		if err := p.OnPage(p); err != nil {
+			return p.newErr(PaginatorStageBeforeNavigate, err)
+		}
+	}
+	if p.BeforeNavigate != nil {
+		if err := p.BeforeNavigate(p, page); err != nil {
+			return p.newErr(PaginatorStageBeforeNavigate, err)
+		}
+	}
+	return nil
+}
+
+func (p *TypedPaginator[T]) afterNavigate() *PaginatorError {
+	if p.AfterNavigate != nil {
+		if err := p.AfterNavigate(p); err != nil {
+			return p.newErr(PaginatorStageAfterNavigate, err)
+		}
+	}
+	return nil
+}
+
+func (p *TypedPaginator[T]) newErr(stage PaginatorStage, err error) *PaginatorError {
+	return &PaginatorError{
+		Stage: stage,
+		Page:  p.page,
+		Err:   err,
+	}
+}
+
+// respond renders the current page as the response to the interaction.
+func (p *TypedPaginator[T]) respond(s *discordgo.Session, i *discordgo.InteractionCreate, respType discordgo.InteractionResponseType) error {
+	data, err := p.renderData()
+	if err != nil {
+		return p.fail(s, i, respType, err)
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: respType,
+		Data: data,
+	})
+}
+
+// fail answers the interaction according to the error policy when the paginator failed before responding.
+func (p *TypedPaginator[T]) fail(s *discordgo.Session, i *discordgo.InteractionCreate, respType discordgo.InteractionResponseType, err *PaginatorError) error {
+	switch p.ErrPolicy {
+	case PaginatorErrPolicyEphemeral:
+		return s.InteractionRespond(i.Interaction, EphemeralResponse(p.errRespData(err, false)))
+	case PaginatorErrPolicyPropagate:
+		if respType == discordgo.InteractionResponseUpdateMessage {
+			respErr := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseDeferredMessageUpdate,
+			})
+			if respErr != nil {
+				return respErr
+			}
+			return err
+		}
+		respErr := s.InteractionRespond(i.Interaction, EphemeralResponse(p.errRespData(err, false)))
+		if respErr != nil {
+			return respErr
+		}
+		return err
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: respType,
+		Data: p.errRespData(err, true),
+	})
+}
+
+// failAfterResponse handles errors of the AfterNavigate hook, which runs once the page was already sent.
+func (p *TypedPaginator[T]) failAfterResponse(s *discordgo.Session, i *discordgo.InteractionCreate, err *PaginatorError) (respErr error) {
+	switch p.ErrPolicy {
+	case PaginatorErrPolicyEphemeral:
+		data := p.errRespData(err, false)
+		_, respErr = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content:         data.Content,
+			Embeds:          data.Embeds,
+			Components:      data.Components,
+			AllowedMentions: data.AllowedMentions,
+			Flags:           discordgo.MessageFlagsEphemeral,
+		})
+		return respErr
+	case PaginatorErrPolicyPropagate:
+		return err
+	}
+	data := p.errRespData(err, true)
+	_, respErr = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:         &data.Content,
+		Embeds:          &data.Embeds,
+		Components:      &data.Components,
+		AllowedMentions: data.AllowedMentions,
+	})
+	return respErr
+}
+
+// errRespData returns a copy of the error response for the stage, with the navigation components added when
+// it replaces the paginator's message.
+func (p *TypedPaginator[T]) errRespData(err *PaginatorError, withComponents bool) *discordgo.InteractionResponseData {
+	var src *discordgo.InteractionResponseData
+	switch {
+	case err.Stage == PaginatorStageBeforeNavigate && p.OnPageErrRespFunc != nil:
+		src = p.OnPageErrRespFunc(p, err)
+	case err.Stage == PaginatorStageBeforeNavigate && p.OnPageErrResp != nil:
+		src = p.OnPageErrResp
+	case err.Stage != PaginatorStageBeforeNavigate && p.OnRespErrRespFunc != nil:
+		src = p.OnRespErrRespFunc(p, err)
+	case err.Stage != PaginatorStageBeforeNavigate && p.OnRespErrResp != nil:
+		src = p.OnRespErrResp
+	case p.ErrRespFunc != nil:
+		src = p.ErrRespFunc(p, err)
+	case p.ErrResp != nil:
+		src = p.ErrResp
+	}
+	data := &discordgo.InteractionResponseData{
+		Content: "Something went wrong while loading this page.",
+		Embeds:  []*discordgo.MessageEmbed{},
+	}
+	if src != nil {
+		*data = *src
+	}
+	if withComponents {
+		data.Components = append(append([]discordgo.MessageComponent{}, data.Components...), p.getComponents()...)
+		data.Flags |= p.getFlags()
+	}
+	return data
+}
@@ -0,0 +1,142 @@
+package disc
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func (p *TypedPaginator[T]) prevID() string {
+	if p.Kind != "" {
+		return persistentPaginatorID(p.Kind, p.page, "prev", p.Key)
+	}
+	return p.customID + "-prev"
+}
+
+func (p *TypedPaginator[T]) nextID() string {
+	if p.Kind != "" {
+		return persistentPaginatorID(p.Kind, p.page, "next", p.Key)
+	}
+	return p.customID + "-next"
+}
+
+func (p *TypedPaginator[T]) handlerIDs() []string {
+	ids := []string{p.prevID(), p.nextID()}
+	if p.selectEnabled() {
+		ids = append(ids, p.selectID())
+	}
+	if p.searchEnabled() {
+		ids = append(ids, p.searchID())
+	}
+	return ids
+}
+
+// Respond sends the initial response for the interaction and tracks it, so the paginator can be locked
+// to the invoking user and its buttons can be disabled once it is closed.
+func (p *TypedPaginator[T]) Respond(s *discordgo.Session, i *discordgo.InteractionCreate) (err error) {
+	p.mu.Lock()
+	if p.OwnerLock && p.ownerID == "" {
+		p.ownerID = GetInteractorUserID(i)
+	}
+	p.mu.Unlock()
+	p.track(i.Interaction)
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	return p.respond(s, i, discordgo.InteractionResponseChannelMessageWithSource)
+}
+
+func (p *TypedPaginator[T]) OwnerID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ownerID
+}
+
+func (p *TypedPaginator[T]) Closed() bool {
+	return p.isClosed()
+}
+
+// Close stops the idle timer, removes the paginator's handlers from the client and disables its buttons.
+func (p *TypedPaginator[T]) Close() (err error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	if p.idleTimer != nil {
+		p.idleTimer.Stop()
+	}
+	p.mu.Unlock()
+	p.Client.RemoveMsgComponentHandlers(p.handlerIDs()...)
+	if p.searchEnabled() {
+		p.Client.RemoveModalSubmitHandlers(p.searchModalID())
+	}
+	p.stateMu.Lock()
+	components := p.getComponents()
+	p.stateMu.Unlock()
+	return p.editComponents(components)
+}
+
+func (p *TypedPaginator[T]) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+func (p *TypedPaginator[T]) guard(s *discordgo.Session, i *discordgo.InteractionCreate) (handled bool, err error) {
+	if p.isClosed() {
+		return true, s.InteractionRespond(i.Interaction, p.UpdateResponse())
+	}
+	p.mu.Lock()
+	if p.OwnerLock && p.ownerID == "" {
+		// sent without Respond, the first user to interact owns the paginator
+		p.ownerID = GetInteractorUserID(i)
+	}
+	locked := p.OwnerLock && p.ownerID != GetInteractorUserID(i)
+	p.mu.Unlock()
+	if locked {
+		return true, s.InteractionRespond(i.Interaction, p.getOwnerLockResp())
+	}
+	p.track(i.Interaction)
+	return false, nil
+}
+
+func (p *TypedPaginator[T]) track(interaction *discordgo.Interaction) {
+	p.mu.Lock()
+	p.interaction = interaction
+	p.mu.Unlock()
+	p.resetIdleTimer()
+}
+
+func (p *TypedPaginator[T]) resetIdleTimer() {
+	if p.IdleTimeout <= 0 || p.Kind != "" {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	if p.idleTimer != nil {
+		p.idleTimer.Reset(p.IdleTimeout)
+		return
+	}
+	p.idleTimer = time.AfterFunc(p.IdleTimeout, func() {
+		err := p.Close()
+		if err == nil || p.Client.HandlerErrCh() == nil {
+			return
+		}
+		// the timer has no caller waiting on it, the error is dropped when nobody reads the channel
+		select {
+		case p.Client.HandlerErrCh() <- err:
+		default:
+		}
+	})
+}
+
+func (p *TypedPaginator[T]) getOwnerLockResp() *discordgo.InteractionResponse {
+	if p.OwnerLockResp != nil {
+		return EphemeralResponse(p.OwnerLockResp)
+	}
+	return EphemeralContentResponse("Only the user who opened this paginator can use it.")
+}
@@ -0,0 +1,132 @@
+package disc
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+func (p *TypedPaginator[T]) MessageSend() *discordgo.MessageSend {
+	return dataToMessageSend(p.Response().Data)
+}
+
+func (p *TypedPaginator[T]) MessageEdit(channelID string, messageID string) *discordgo.MessageEdit {
+	return dataToMessageEdit(p.UpdateResponse().Data, channelID, messageID)
+}
+
+func (p *TypedPaginator[T]) WebhookParams() *discordgo.WebhookParams {
+	return dataToWebhookParams(p.Response().Data)
+}
+
+func dataToMessageSend(data *discordgo.InteractionResponseData) *discordgo.MessageSend {
+	return &discordgo.MessageSend{
+		Content:         data.Content,
+		Embeds:          data.Embeds,
+		Components:      data.Components,
+		AllowedMentions: data.AllowedMentions,
+		Files:           data.Files,
+	}
+}
+
+func dataToMessageEdit(data *discordgo.InteractionResponseData, channelID string, messageID string) *discordgo.MessageEdit {
+	edit := discordgo.NewMessageEdit(channelID, messageID)
+	edit.Content = &data.Content
+	edit.Embeds = &data.Embeds
+	edit.Components = &data.Components
+	edit.AllowedMentions = data.AllowedMentions
+	return edit
+}
+
+func dataToWebhookParams(data *discordgo.InteractionResponseData) *discordgo.WebhookParams {
+	return &discordgo.WebhookParams{
+		Content:         data.Content,
+		Embeds:          data.Embeds,
+		Components:      data.Components,
+		AllowedMentions: data.AllowedMentions,
+		Files:           data.Files,
+	}
+}
+
+// Send posts the paginator as a regular channel message, button clicks then update that message.
+func (p *TypedPaginator[T]) Send(channelID string) (m *discordgo.Message, err error) {
+	data, err := p.Render()
+	if err != nil {
+		return nil, err
+	}
+	m, err = p.Client.Sess().ChannelMessageSendComplex(channelID, dataToMessageSend(data))
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.channelID = m.ChannelID
+	p.messageID = m.ID
+	p.mu.Unlock()
+	p.resetIdleTimer()
+	return m, nil
+}
+
+// SendWebhook posts the paginator through a webhook, which must be owned by the application for the
+// buttons to be usable.
+func (p *TypedPaginator[T]) SendWebhook(webhookID string, token string) (m *discordgo.Message, err error) {
+	data, err := p.Render()
+	if err != nil {
+		return nil, err
+	}
+	m, err = p.Client.Sess().WebhookExecute(webhookID, token, true, dataToWebhookParams(data))
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.channelID = m.ChannelID
+	p.messageID = m.ID
+	p.webhookID = webhookID
+	p.webhookToken = token
+	p.mu.Unlock()
+	p.resetIdleTimer()
+	return m, nil
+}
+
+// Edit re-renders the current page into the message posted with Send or SendWebhook.
+func (p *TypedPaginator[T]) Edit() (err error) {
+	p.mu.Lock()
+	channelID, messageID, webhookID, webhookToken := p.channelID, p.messageID, p.webhookID, p.webhookToken
+	p.mu.Unlock()
+	if messageID == "" {
+		return ErrPaginatorNotSent
+	}
+	data, err := p.Render()
+	if err != nil {
+		return err
+	}
+	edit := dataToMessageEdit(data, channelID, messageID)
+	if webhookID != "" {
+		_, err = p.Client.Sess().WebhookMessageEdit(webhookID, webhookToken, messageID, &discordgo.WebhookEdit{
+			Content:         edit.Content,
+			Components:      edit.Components,
+			Embeds:          edit.Embeds,
+			AllowedMentions: edit.AllowedMentions,
+		})
+		return err
+	}
+	_, err = p.Client.Sess().ChannelMessageEditComplex(edit)
+	return err
+}
+
+func (p *TypedPaginator[T]) editComponents(components []discordgo.MessageComponent) (err error) {
+	p.mu.Lock()
+	interaction, channelID, messageID, webhookID, webhookToken := p.interaction, p.channelID, p.messageID, p.webhookID, p.webhookToken
+	p.mu.Unlock()
+	switch {
+	case webhookID != "":
+		_, err = p.Client.Sess().WebhookMessageEdit(webhookID, webhookToken, messageID, &discordgo.WebhookEdit{
+			Components: &components,
+		})
+	case messageID != "":
+		edit := discordgo.NewMessageEdit(channelID, messageID)
+		edit.Components = &components
+		_, err = p.Client.Sess().ChannelMessageEditComplex(edit)
+	case interaction != nil:
+		_, err = p.Client.Sess().InteractionResponseEdit(interaction, &discordgo.WebhookEdit{
+			Components: &components,
+		})
+	}
+	return err
+}
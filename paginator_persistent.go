@@ -0,0 +1,97 @@
+package disc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const persistentPaginatorPrefix = "disc-pg"
+
+// PaginatorFactory re-creates a persistent paginator of a kind from the key encoded in its buttons' custom IDs.
+type PaginatorFactory[T any] func(data MsgComponentHandlerData, key string) (p *TypedPaginator[T], err error)
+
+// AddPaginatorFactory registers the factory for persistent paginators of the kind, so their buttons keep
+// working after the bot restarts. The kind must not contain a colon.
+func AddPaginatorFactory[T any](c *Client, kind string, factory PaginatorFactory[T], handlerErrCh ...chan error) {
+	c.AddMsgComponentHandler(persistentPaginatorHandlerName(kind), persistentPaginatorHandler(factory), handlerErrCh...)
+}
+
+// AddGroupPaginatorFactory registers the factory on a GroupHandler, see AddPaginatorFactory.
+func AddGroupPaginatorFactory[T any](h *GroupHandler, kind string, factory PaginatorFactory[T], errCh ...chan error) {
+	h.AddMsgComponentHandler(persistentPaginatorHandlerName(kind), persistentPaginatorHandler(factory), errCh...)
+}
+
+func persistentPaginatorHandler[T any](factory PaginatorFactory[T]) MsgComponentHandler {
+	return func(data MsgComponentHandlerData) error {
+		_, page, action, key, err := parsePersistentPaginatorID(data.Data.CustomID)
+		if err != nil {
+			return err
+		}
+		p, err := factory(data, key)
+		if err != nil {
+			return err
+		}
+		p.page = page
+		switch action {
+		case "prev":
+			return p.prev(data)
+		case "next":
+			return p.next(data)
+		case "select":
+			return p.selectPage(data)
+		}
+		return fmt.Errorf("unknown paginator action %q", action)
+	}
+}
+
+func (c *Client) RemovePaginatorFactories(kinds ...string) {
+	for _, kind := range kinds {
+		c.RemoveMsgComponentHandlers(persistentPaginatorHandlerName(kind))
+	}
+}
+
+func persistentPaginatorHandlerName(kind string) string {
+	return persistentPaginatorPrefix + ":" + kind
+}
+
+func persistentPaginatorID(kind string, page int, action string, key string) string {
+	return fmt.Sprintf("%s:%s:%d:%s:%s", persistentPaginatorPrefix, kind, page, action, key)
+}
+
+func parsePersistentPaginatorID(customID string) (kind string, page int, action string, key string, err error) {
+	parts := strings.SplitN(customID, ":", 5)
+	if len(parts) != 5 || parts[0] != persistentPaginatorPrefix {
+		return kind, page, action, key, fmt.Errorf("invalid persistent paginator custom id %q", customID)
+	}
+	page, err = strconv.Atoi(parts[2])
+	if err != nil || page < 1 {
+		return kind, page, action, key, fmt.Errorf("invalid page in persistent paginator custom id %q", customID)
+	}
+	return parts[1], page, parts[3], parts[4], nil
+}
+
+// persistentPaginatorName returns the name of the handler for the custom ID, which is the factory of the
+// paginator's kind for persistent paginators.
+func persistentPaginatorName(customID string) string {
+	if kind, _, _, _, err := parsePersistentPaginatorID(customID); err == nil {
+		return persistentPaginatorHandlerName(kind)
+	}
+	return customID
+}
+
+// validateCustomIDs checks the custom IDs of a persistent paginator against Discord's limit, they grow with
+// its kind and key.
+func (p *TypedPaginator[T]) validateCustomIDs() error {
+	if p.Kind == "" {
+		return nil
+	}
+	errs := []error{}
+	for _, id := range p.handlerIDs() {
+		if len(id) > LimitCustomID {
+			errs = append(errs, &LimitError{Path: "custom_id " + id, Limit: LimitCustomID, Got: len(id)})
+		}
+	}
+	return errors.Join(errs...)
+}
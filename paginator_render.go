@@ -0,0 +1,63 @@
+package disc
+
+import (
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// render builds the page with the render function when set, otherwise from the content and embed fields.
+// The paginator's navigation components and flags are always added.
+func (p *TypedPaginator[T]) render() *discordgo.InteractionResponseData {
+	if p.RenderFunc != nil {
+		data := &discordgo.InteractionResponseData{}
+		if rendered := p.RenderFunc(p); rendered != nil {
+			*data = *rendered
+		}
+		data.Components = append(slices.Clone(data.Components), p.getComponents()...)
+		data.Flags |= p.getFlags()
+		return data
+	}
+	return &discordgo.InteractionResponseData{
+		Content:    p.getContent(),
+		Embeds:     p.getEmbeds(),
+		Components: p.getComponents(),
+		Flags:      p.getFlags(),
+	}
+}
+
+func (p *TypedPaginator[T]) getContent() string {
+	if p.ContentFunc != nil {
+		return p.ContentFunc(p)
+	}
+	return p.Content
+}
+
+func (p *TypedPaginator[T]) getEmbeds() []*discordgo.MessageEmbed {
+	if p.PlainText {
+		return []*discordgo.MessageEmbed{}
+	}
+	if p.EmbedsFunc != nil {
+		embeds := p.EmbedsFunc(p)
+		if embeds == nil {
+			return []*discordgo.MessageEmbed{}
+		}
+		return embeds
+	}
+	return []*discordgo.MessageEmbed{
+		{
+			URL:         p.getURL(),
+			Type:        p.getType(),
+			Title:       p.getTitle(),
+			Description: p.getDesc(),
+			Timestamp:   p.getTimestamp(),
+			Footer:      p.getFooter(),
+			Image:       p.getImage(),
+			Thumbnail:   p.getThumbnail(),
+			Video:       p.getVideo(),
+			Provider:    p.getProvider(),
+			Author:      p.getAuthor(),
+			Fields:      p.getFields(),
+		},
+	}
+}
@@ -0,0 +1,266 @@
+package disc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	paginatorSelectMaxOptions = 25
+	paginatorSearchInputID    = "query"
+)
+
+type PaginatorCategory struct {
+	Label       string
+	Description string
+	Page        int
+}
+
+func (p *TypedPaginator[T]) selectID() string {
+	if p.Kind != "" {
+		return persistentPaginatorID(p.Kind, p.page, "select", p.Key)
+	}
+	return p.customID + "-select"
+}
+
+func (p *TypedPaginator[T]) searchID() string {
+	return p.customID + "-search"
+}
+
+func (p *TypedPaginator[T]) searchModalID() string {
+	return p.customID + "-search-modal"
+}
+
+func (p *TypedPaginator[T]) selectEnabled() bool {
+	return p.PageSelect || p.Categories != nil || p.CategoriesFunc != nil
+}
+
+// Searching keeps the query in memory, so it is not available for persistent paginators.
+func (p *TypedPaginator[T]) searchEnabled() bool {
+	return p.queryEnabled() && p.Kind == ""
+}
+
+// queryEnabled reports whether a query filters the paginator, through the filter function for items and
+// through the source itself when it implements TypedPaginatorQuerySource.
+func (p *TypedPaginator[T]) queryEnabled() bool {
+	if p.source != nil {
+		_, ok := p.source.(TypedPaginatorQuerySource[T])
+		return ok
+	}
+	return p.FilterFunc != nil
+}
+
+func (p *TypedPaginator[T]) getCategories() []PaginatorCategory {
+	if p.CategoriesFunc != nil {
+		return p.CategoriesFunc(p)
+	}
+	return p.Categories
+}
+
+func (p *TypedPaginator[T]) getSelectMenu() (menu discordgo.SelectMenu, ok bool) {
+	if !p.selectEnabled() {
+		return menu, false
+	}
+	options := []discordgo.SelectMenuOption{}
+	placeholder := "Jump to page"
+	if categories := p.getCategories(); categories != nil {
+		placeholder = "Jump to category"
+		selected := -1
+		for i, category := range categories {
+			if category.Page <= p.page {
+				selected = i
+			}
+		}
+		for i, category := range categories {
+			if len(options) == paginatorSelectMaxOptions {
+				break
+			}
+			options = append(options, discordgo.SelectMenuOption{
+				Label:       category.Label,
+				Description: category.Description,
+				Value:       fmt.Sprintf("%d:%d", i, category.Page),
+				Default:     i == selected,
+			})
+		}
+	} else {
+		last := p.LastPage()
+		if last == UnknownLastPage {
+			last = p.page
+			if p.HasNextPage() {
+				last++
+			}
+		}
+		end := min(last, max(1, p.page-paginatorSelectMaxOptions/2)+paginatorSelectMaxOptions-1)
+		start := max(1, end-paginatorSelectMaxOptions+1)
+		for page := start; page <= end; page++ {
+			options = append(options, discordgo.SelectMenuOption{
+				Label:   fmt.Sprintf("Page %d", page),
+				Value:   strconv.Itoa(page),
+				Default: page == p.page,
+			})
+		}
+	}
+	if len(options) == 0 {
+		return menu, false
+	}
+	return discordgo.SelectMenu{
+		MenuType:    discordgo.StringSelectMenu,
+		CustomID:    p.selectID(),
+		Placeholder: placeholder,
+		Options:     options,
+		Disabled:    p.isClosed(),
+	}, true
+}
+
+func (p *TypedPaginator[T]) selectPage(data MsgComponentHandlerData) error {
+	if len(data.Data.Values) == 0 {
+		return errors.New("no paginator page selected")
+	}
+	value := data.Data.Values[0]
+	if _, page, ok := strings.Cut(value, ":"); ok {
+		value = page
+	}
+	page, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	return p.navigate(data.S, data.I, func() int {
+		return page
+	})
+}
+
+func (p *TypedPaginator[T]) openSearch(data MsgComponentHandlerData) error {
+	if handled, err := p.guard(data.S, data.I); handled {
+		return err
+	}
+	p.stateMu.Lock()
+	query := p.query
+	p.stateMu.Unlock()
+	// the modal is answered through the paginator's client, so the handlers returned by Build are enough
+	if p.Client != nil {
+		p.Client.AddModalSubmitHandlers(p.ModalSubmitHandlers())
+	}
+	return data.S.InteractionRespond(data.I.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: p.searchModalID(),
+			Title:    "Search",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    paginatorSearchInputID,
+							Label:       "Search",
+							Style:       discordgo.TextInputShort,
+							Placeholder: "Leave empty to clear the filter",
+							Value:       query,
+							MaxLength:   100,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+func (p *TypedPaginator[T]) submitSearch(data ModalSubmitHandlerData) error {
+	if handled, err := p.guard(data.S, data.I); handled {
+		return err
+	}
+	query := ""
+	for _, row := range data.Data.Components {
+		if row, ok := row.(*discordgo.ActionsRow); ok {
+			for _, component := range row.Components {
+				if input, ok := component.(*discordgo.TextInput); ok && input.CustomID == paginatorSearchInputID {
+					query = input.Value
+				}
+			}
+		}
+	}
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	p.setQuery(query)
+	return p.respond(data.S, data.I, discordgo.InteractionResponseUpdateMessage)
+}
+
+// ModalSubmitHandlers returns the handlers for the search modal. The search button adds them to the paginator's
+// client when it opens the modal, they only have to be added to a GroupHandler dispatching the interactions.
+func (p *TypedPaginator[T]) ModalSubmitHandlers() map[string]ModalSubmitHandler {
+	if !p.searchEnabled() {
+		return map[string]ModalSubmitHandler{}
+	}
+	return map[string]ModalSubmitHandler{
+		p.searchModalID(): p.submitSearch,
+	}
+}
+
+// Query returns the search query. Like the other getters it reads the state without locking it, so it must
+// only be called from the paginator's hooks and render functions, which run while the state is locked, or
+// before the paginator handles interactions. The getters never write to the state.
+func (p *TypedPaginator[T]) Query() string {
+	return p.query
+}
+
+// SetQuery filters the items with the filter function, or passes the query to a TypedPaginatorQuerySource,
+// and resets the paginator to the first page. It does nothing when neither is set.
+func (p *TypedPaginator[T]) SetQuery(query string) {
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	p.setQuery(query)
+}
+
+func (p *TypedPaginator[T]) setQuery(query string) {
+	if !p.queryEnabled() {
+		return
+	}
+	p.query = strings.TrimSpace(query)
+	p.page = 1
+	if p.source != nil {
+		p.resetSource()
+		return
+	}
+	p.applyFilter()
+}
+
+// applyFilter recomputes the filtered items and the split pages, it runs whenever the items or the query
+// change so the getters never write to the state.
+func (p *TypedPaginator[T]) applyFilter() {
+	p.filtered = nil
+	if p.query != "" && p.FilterFunc != nil {
+		for _, item := range p.items {
+			if p.FilterFunc(item, p.query) {
+				p.filtered = append(p.filtered, item)
+			}
+		}
+	}
+	p.bounds = nil
+	if p.autoSplit() {
+		p.bounds = p.splitBounds()
+	}
+}
+
+func (p *TypedPaginator[T]) viewItems() []T {
+	if p.query == "" || p.FilterFunc == nil {
+		return p.items
+	}
+	return p.filtered
+}
+
+func (p *TypedPaginator[T]) withQueryFooter(footer *discordgo.MessageEmbedFooter) *discordgo.MessageEmbedFooter {
+	if p.query == "" || !p.queryEnabled() {
+		return footer
+	}
+	text := fmt.Sprintf("Filter: %s", p.query)
+	if footer == nil {
+		return &discordgo.MessageEmbedFooter{Text: text}
+	}
+	return &discordgo.MessageEmbedFooter{
+		Text:         footer.Text + " • " + text,
+		IconURL:      footer.IconURL,
+		ProxyIconURL: footer.ProxyIconURL,
+	}
+}
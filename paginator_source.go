@@ -0,0 +1,181 @@
+package disc
+
+const UnknownLastPage = -1
+
+const defaultPaginatorSourceCacheSize = 5
+
+type PaginatorSource = TypedPaginatorSource[any]
+
+type PaginatorFetchFunc = TypedPaginatorFetchFunc[any]
+
+type PaginatorQuerySource = TypedPaginatorQuerySource[any]
+
+type PaginatorQueryFetchFunc = TypedPaginatorQueryFetchFunc[any]
+
+type TypedPaginatorSource[T any] interface {
+	// Fetch returns the items of the 1-indexed page, at most perPage of them.
+	Fetch(page int, perPage int) (items []T, err error)
+	// Total returns the total amount of items, ok is false when it is unknown.
+	Total() (total int, ok bool, err error)
+}
+
+type TypedPaginatorFetchFunc[T any] func(page int, perPage int) (items []T, err error)
+
+func (f TypedPaginatorFetchFunc[T]) Fetch(page int, perPage int) ([]T, error) {
+	return f(page, perPage)
+}
+
+func (f TypedPaginatorFetchFunc[T]) Total() (int, bool, error) {
+	return 0, false, nil
+}
+
+// TypedPaginatorQuerySource is a source filtering its items by the search query itself, paginators with a
+// source only offer search when it implements this interface.
+type TypedPaginatorQuerySource[T any] interface {
+	TypedPaginatorSource[T]
+	// FetchQuery returns the items of the 1-indexed page of the items matching the query.
+	FetchQuery(query string, page int, perPage int) (items []T, err error)
+	// TotalQuery returns the total amount of items matching the query, ok is false when it is unknown.
+	TotalQuery(query string) (total int, ok bool, err error)
+}
+
+// TypedPaginatorQueryFetchFunc is a query source fetching every page with the function, an empty query
+// fetches the unfiltered items.
+type TypedPaginatorQueryFetchFunc[T any] func(query string, page int, perPage int) (items []T, err error)
+
+func (f TypedPaginatorQueryFetchFunc[T]) Fetch(page int, perPage int) ([]T, error) {
+	return f("", page, perPage)
+}
+
+func (f TypedPaginatorQueryFetchFunc[T]) Total() (int, bool, error) {
+	return 0, false, nil
+}
+
+func (f TypedPaginatorQueryFetchFunc[T]) FetchQuery(query string, page int, perPage int) ([]T, error) {
+	return f(query, page, perPage)
+}
+
+func (f TypedPaginatorQueryFetchFunc[T]) TotalQuery(query string) (int, bool, error) {
+	return 0, false, nil
+}
+
+type paginatorCache[T any] struct {
+	size  int
+	pages map[int][]T
+	order []int
+}
+
+func newPaginatorCache[T any](size int) *paginatorCache[T] {
+	if size <= 0 {
+		size = defaultPaginatorSourceCacheSize
+	}
+	return &paginatorCache[T]{
+		size:  size,
+		pages: map[int][]T{},
+	}
+}
+
+func (c *paginatorCache[T]) get(page int) ([]T, bool) {
+	items, ok := c.pages[page]
+	if ok {
+		c.touch(page)
+	}
+	return items, ok
+}
+
+func (c *paginatorCache[T]) set(page int, items []T) {
+	if _, ok := c.pages[page]; !ok && len(c.order) >= c.size {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.pages, oldest)
+	}
+	c.pages[page] = items
+	c.touch(page)
+}
+
+func (c *paginatorCache[T]) touch(page int) {
+	for i, v := range c.order {
+		if v == page {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+	c.order = append(c.order, page)
+}
+
+func (c *paginatorCache[T]) clear() {
+	c.pages = map[int][]T{}
+	c.order = nil
+}
+
+func (p *TypedPaginator[T]) fetch(page int) (items []T, err error) {
+	if items, ok := p.cache.get(page); ok {
+		return items, nil
+	}
+	if qs, ok := p.source.(TypedPaginatorQuerySource[T]); ok && p.query != "" {
+		items, err = qs.FetchQuery(p.query, page, p.perPage)
+	} else {
+		items, err = p.source.Fetch(page, p.perPage)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.cache.set(page, items)
+	return items, nil
+}
+
+func (p *TypedPaginator[T]) load() (err error) {
+	if p.source == nil {
+		return nil
+	}
+	if !p.totalLoaded {
+		if qs, ok := p.source.(TypedPaginatorQuerySource[T]); ok && p.query != "" {
+			p.total, p.totalKnown, err = qs.TotalQuery(p.query)
+		} else {
+			p.total, p.totalKnown, err = p.source.Total()
+		}
+		if err != nil {
+			return err
+		}
+		p.totalLoaded = true
+	}
+	p.curItems, err = p.fetch(p.page)
+	if err != nil {
+		return err
+	}
+	if p.totalKnown {
+		p.hasNext = p.page < p.LastPage()
+		return nil
+	}
+	if len(p.curItems) < p.perPage {
+		p.hasNext = false
+		p.lastPage = p.page
+		return nil
+	}
+	next, err := p.fetch(p.page + 1)
+	if err != nil {
+		return err
+	}
+	p.hasNext = len(next) > 0
+	if !p.hasNext {
+		p.lastPage = p.page
+	}
+	return nil
+}
+
+// Refresh drops the cached pages and total so they are fetched again from the source on the next render.
+func (p *TypedPaginator[T]) Refresh() {
+	if p.source == nil {
+		return
+	}
+	p.stateMu.Lock()
+	defer p.stateMu.Unlock()
+	p.resetSource()
+}
+
+func (p *TypedPaginator[T]) resetSource() {
+	p.cache.clear()
+	p.totalLoaded = false
+	p.totalKnown = false
+	p.lastPage = 0
+}
@@ -0,0 +1,40 @@
+package disc
+
+func (p *TypedPaginator[T]) autoSplit() bool {
+	return p.SizeFunc != nil && p.MaxPageSize > 0
+}
+
+// splitBounds computes the index of the first item of every page when splitting by size, the result is kept
+// in p.bounds by applyFilter.
+func (p *TypedPaginator[T]) splitBounds() []int {
+	bounds := []int{}
+	size, count := 0, 0
+	for i, item := range p.viewItems() {
+		itemSize := p.SizeFunc(item)
+		if len(bounds) == 0 || size+itemSize > p.MaxPageSize || (p.perPage > 0 && count == p.perPage) {
+			bounds = append(bounds, i)
+			size, count = 0, 0
+		}
+		size += itemSize
+		count++
+	}
+	return bounds
+}
+
+func (p *TypedPaginator[T]) pageRange(page int) (start int, end int) {
+	items := p.viewItems()
+	if p.autoSplit() {
+		bounds := p.bounds
+		if page < 1 || page > len(bounds) {
+			return len(items), len(items)
+		}
+		start, end = bounds[page-1], len(items)
+		if page < len(bounds) {
+			end = bounds[page]
+		}
+		return start, end
+	}
+	start = min(max(page-1, 0)*p.perPage, len(items))
+	end = min(start+p.perPage, len(items))
+	return start, end
+}
@@ -1,8 +1,16 @@
 package disc_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/matryer/is"
@@ -60,3 +68,677 @@ func TestPaginator(t *testing.T) {
 	}, handlerErrCh)
 	<-make(chan struct{})
 }
+
+func TestTypedPaginator(t *testing.T) {
+	list := make([]string, 20)
+	for i := 0; i < 20; i++ {
+		list[i] = fmt.Sprintf("String %d", i+1)
+	}
+	is := is.New(t)
+	c, err := createAndStartBotClient()
+	is.NoErr(err)
+	defer c.Close()
+	handlerErrCh := make(chan error)
+	go func() {
+		for err := range handlerErrCh {
+			t.Log(err)
+		}
+	}()
+	c.StartCmds(&discordgo.ApplicationCommand{
+		Name:        "paginator",
+		Description: "Paginator command",
+	})
+	c.Handle()
+	c.AddAppCmdHandler("paginator", func(data disc.AppCmdHandlerData) error {
+		paginator, paginatorHandlers := disc.NewTypedPaginatorBuilder[string](c).
+			SetTitle("Paginator").
+			SetDesc("This is a paginator message").
+			SetFieldsFunc(func(p *disc.TypedPaginator[string]) []*discordgo.MessageEmbedField {
+				fields := []*discordgo.MessageEmbedField{}
+				for i, item := range p.CurPageItems() {
+					fields = append(fields, &discordgo.MessageEmbedField{
+						Name:   fmt.Sprintf("Field %d", i+1),
+						Value:  item,
+						Inline: false,
+					})
+				}
+				return fields
+			}).
+			SetFooterFunc(func(p *disc.TypedPaginator[string]) *discordgo.MessageEmbedFooter {
+				return &discordgo.MessageEmbedFooter{
+					Text: fmt.Sprintf("Page %d of %d", p.Page(), p.LastPage()),
+				}
+			}).
+			SetInitialItems(list).
+			UseEphemeralResponse().
+			UseOwnerLock().
+			SetIdleTimeout(time.Minute).
+			Build(5)
+
+		c.AddMsgComponentHandlers(paginatorHandlers, handlerErrCh)
+		return paginator.Respond(data.S, data.I)
+	}, handlerErrCh)
+	<-make(chan struct{})
+}
+
+func TestPaginatorSource(t *testing.T) {
+	is := is.New(t)
+	c, err := disc.NewClient("", "")
+	is.NoErr(err)
+	fetches := 0
+	p, _ := c.NewPaginatorBuilder().
+		SetSource(disc.PaginatorFetchFunc(func(page int, perPage int) ([]any, error) {
+			fetches++
+			items := []any{}
+			for i := (page - 1) * perPage; i < page*perPage && i < 12; i++ {
+				items = append(items, fmt.Sprintf("String %d", i+1))
+			}
+			return items, nil
+		})).
+		SetFooterFunc(func(p *disc.Paginator) *discordgo.MessageEmbedFooter {
+			return &discordgo.MessageEmbedFooter{
+				Text: fmt.Sprintf("Page %d of %s", p.Page(), p.LastPageStr()),
+			}
+		}).
+		Build(5)
+
+	resp := p.Response()
+	is.Equal(resp.Data.Embeds[0].Footer.Text, "Page 1 of ?")
+	is.Equal(p.CurPageItems(), []any{"String 1", "String 2", "String 3", "String 4", "String 5"})
+	is.True(p.HasNextPage())
+	is.Equal(fetches, 2) // current page and the prefetched next page
+}
+
+func TestPaginatorClose(t *testing.T) {
+	is := is.New(t)
+	c, err := disc.NewClient("", "")
+	is.NoErr(err)
+	p, handlers := c.NewPaginatorBuilder().
+		SetInitialItems([]any{"String 1", "String 2"}).
+		Build(1)
+	c.AddMsgComponentHandlers(handlers)
+	is.Equal(len(c.MsgComponentHandlers()), 2)
+	is.NoErr(p.Close())
+	is.True(p.Closed())
+	is.Equal(len(c.MsgComponentHandlers()), 0)
+	is.NoErr(p.Close())
+}
+
+func TestPaginatorIdleTimeout(t *testing.T) {
+	is := is.New(t)
+	c := newSyntheticClient(t, nil)
+	c.SetHandlerErrorCh(make(chan error)) // nobody reads the expiry errors
+	p, _ := c.NewPaginatorBuilder().
+		SetInitialItems([]any{"String 1", "String 2"}).
+		SetIdleTimeout(time.Millisecond * 10).
+		Build(1)
+	time.Sleep(time.Millisecond * 50)
+	is.True(!p.Closed()) // not sent yet
+	is.NoErr(p.Respond(c.Sess(), syntheticClick(c, "paginator", "user").I))
+	is.True(!p.Closed())
+	time.Sleep(time.Millisecond * 50)
+	is.True(p.Closed())
+}
+
+func TestPaginatorPersistent(t *testing.T) {
+	is := is.New(t)
+	c, err := disc.NewClient("", "")
+	is.NoErr(err)
+	p, handlers := c.NewPaginatorBuilder().
+		SetInitialItems([]any{"String 1", "String 2"}).
+		SetPersistent("leaderboard", "guild-1").
+		Build(1)
+	is.Equal(len(handlers), 0)
+	row := p.Response().Data.Components[0].(discordgo.ActionsRow)
+	is.Equal(row.Components[0].(discordgo.Button).CustomID, "disc-pg:leaderboard:1:prev:guild-1")
+	is.Equal(row.Components[1].(discordgo.Button).CustomID, "disc-pg:leaderboard:1:next:guild-1")
+
+	p, _ = c.NewPaginatorBuilder().
+		SetInitialItems([]any{"String 1", "String 2"}).
+		SetPersistent("leaderboard", strings.Repeat("k", 80)).
+		Build(1)
+	_, err = p.Render()
+	limitErr := &disc.LimitError{}
+	is.True(errors.As(err, &limitErr)) // the key does not fit in a custom ID
+	is.Equal(limitErr.Limit, disc.LimitCustomID)
+}
+
+func TestPaginatorSearch(t *testing.T) {
+	is := is.New(t)
+	c, err := disc.NewClient("", "")
+	is.NoErr(err)
+	list := make([]string, 20)
+	for i := 0; i < 20; i++ {
+		list[i] = fmt.Sprintf("String %d", i+1)
+	}
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems(list).
+		SetFooter(&discordgo.MessageEmbedFooter{Text: "Strings"}).
+		SetFilterFunc(func(item string, query string) bool {
+			return strings.Contains(item, query)
+		}).
+		UsePageSelect().
+		Build(5)
+	is.Equal(len(handlers), 4)
+	is.Equal(len(p.ModalSubmitHandlers()), 1)
+
+	resp := p.Response()
+	menu := resp.Data.Components[0].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	is.Equal(len(menu.Options), 4)
+	is.True(menu.Options[0].Default)
+
+	p.SetQuery("1")
+	is.Equal(p.Page(), 1)
+	is.Equal(p.LastPage(), 3) // 1, 10-19
+	is.Equal(p.CurPageItems(), []string{"String 1", "String 10", "String 11", "String 12", "String 13"})
+	is.Equal(p.Response().Data.Embeds[0].Footer.Text, "Strings • Filter: 1")
+}
+
+func TestPaginatorSearchModal(t *testing.T) {
+	is := is.New(t)
+	resps := []syntheticResponse{}
+	c := newSyntheticClient(t, func(resp syntheticResponse) {
+		resps = append(resps, resp)
+	})
+	list := make([]string, 20)
+	for i := 0; i < 20; i++ {
+		list[i] = fmt.Sprintf("String %d", i+1)
+	}
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems(list).
+		SetFilterFunc(func(item string, query string) bool {
+			return strings.Contains(item, query)
+		}).
+		Build(5)
+	c.AddMsgComponentHandlers(handlers)
+	searchID, search := handlerBySuffix(handlers, "-search")
+	is.NoErr(search(syntheticClick(c, searchID, "user")))
+	is.Equal(resps[0].Type, discordgo.InteractionResponseModal)
+
+	// only the component handlers were added, opening the modal registered its submit handler
+	modalID := resps[0].Data.CustomID
+	submit, ok := c.ModalSubmitHandlers()[modalID]
+	is.True(ok)
+	i := &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:    "interaction",
+			AppID: "app",
+			Token: "token",
+			Type:  discordgo.InteractionModalSubmit,
+			Data: discordgo.ModalSubmitInteractionData{
+				CustomID: modalID,
+				Components: []discordgo.MessageComponent{
+					&discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+						&discordgo.TextInput{CustomID: "query", Value: "1"},
+					}},
+				},
+			},
+			User: &discordgo.User{ID: "user"},
+		},
+	}
+	is.NoErr(submit(disc.ModalSubmitHandlerData{C: c, S: c.Sess(), I: i, Data: i.ModalSubmitData()}))
+	is.Equal(p.Query(), "1")
+	is.Equal(p.LastPage(), 3)
+
+	p.Close()
+	_, ok = c.ModalSubmitHandlers()[modalID]
+	is.True(!ok)
+}
+
+func TestPaginatorSourceSearch(t *testing.T) {
+	is := is.New(t)
+	c, err := disc.NewClient("", "")
+	is.NoErr(err)
+	fetch := func(query string, page int, perPage int) ([]string, error) {
+		matches := []string{}
+		for i := 1; i <= 20; i++ {
+			if item := fmt.Sprintf("String %d", i); strings.Contains(item, query) {
+				matches = append(matches, item)
+			}
+		}
+		return matches[min(len(matches), (page-1)*perPage):min(len(matches), page*perPage)], nil
+	}
+
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetSource(disc.TypedPaginatorFetchFunc[string](func(page int, perPage int) ([]string, error) {
+			return fetch("", page, perPage)
+		})).
+		SetFilterFunc(func(item string, query string) bool {
+			return strings.Contains(item, query)
+		}).
+		Build(5)
+	is.Equal(len(handlers), 2) // no search, the source cannot filter
+	is.Equal(len(p.ModalSubmitHandlers()), 0)
+	p.SetQuery("zzz")
+	is.Equal(p.Query(), "")
+
+	p, handlers = disc.NewTypedPaginatorBuilder[string](c).
+		SetSource(disc.TypedPaginatorQueryFetchFunc[string](fetch)).
+		SetFooter(&discordgo.MessageEmbedFooter{Text: "Strings"}).
+		Build(5)
+	is.Equal(len(handlers), 3)
+	is.Equal(p.Response().Data.Embeds[0].Footer.Text, "Strings")
+	p.SetQuery("1")
+	resp := p.Response()
+	is.Equal(p.CurPageItems(), []string{"String 1", "String 10", "String 11", "String 12", "String 13"})
+	is.Equal(resp.Data.Embeds[0].Footer.Text, "Strings • Filter: 1")
+	p.SetQuery("zzz")
+	p.Response()
+	is.Equal(len(p.CurPageItems()), 0)
+}
+
+func TestPaginatorRender(t *testing.T) {
+	is := is.New(t)
+	c, err := disc.NewClient("", "")
+	is.NoErr(err)
+	list := []string{"String 1", "String 2", "String 3"}
+
+	p, _ := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems(list).
+		SetEmbedsFunc(func(p *disc.TypedPaginator[string]) (embeds []*discordgo.MessageEmbed) {
+			for _, item := range p.CurPageItems() {
+				embeds = append(embeds, &discordgo.MessageEmbed{Title: item})
+			}
+			return embeds
+		}).
+		Build(2)
+	resp := p.Response()
+	is.Equal(len(resp.Data.Embeds), 2)
+	is.Equal(resp.Data.Embeds[1].Title, "String 2")
+
+	p, _ = disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems(list).
+		SetContentFunc(func(p *disc.TypedPaginator[string]) string {
+			return strings.Join(p.CurPageItems(), "\n")
+		}).
+		UsePlainText().
+		Build(2)
+	resp = p.Response()
+	is.Equal(len(resp.Data.Embeds), 0)
+	is.Equal(resp.Data.Content, "String 1\nString 2")
+
+	p, _ = disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems(list).
+		SetRenderFunc(func(p *disc.TypedPaginator[string]) *discordgo.InteractionResponseData {
+			return &discordgo.InteractionResponseData{Content: fmt.Sprintf("Page %d", p.Page())}
+		}).
+		UseEphemeralResponse().
+		Build(2)
+	resp = p.Response()
+	is.Equal(resp.Data.Content, "Page 1")
+	is.Equal(len(resp.Data.Components), 1)
+	is.Equal(resp.Data.Flags, discordgo.MessageFlagsEphemeral)
+}
+
+func TestPaginatorMessage(t *testing.T) {
+	is := is.New(t)
+	c, err := disc.NewClient("", "")
+	is.NoErr(err)
+	p, _ := c.NewPaginatorBuilder().
+		SetTitle("Leaderboard").
+		SetInitialItems([]any{"String 1", "String 2"}).
+		UseEphemeralResponse().
+		Build(1)
+	msg := p.MessageSend()
+	is.Equal(msg.Embeds[0].Title, "Leaderboard")
+	is.Equal(len(msg.Components), 1)
+	edit := p.MessageEdit("channel", "message")
+	is.Equal(edit.Channel, "channel")
+	is.Equal(edit.ID, "message")
+	is.Equal((*edit.Embeds)[0].Title, "Leaderboard")
+	is.Equal(p.Edit(), disc.ErrPaginatorNotSent)
+}
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+type syntheticResponse struct {
+	Type discordgo.InteractionResponseType `json:"type"`
+	Data struct {
+		CustomID string                    `json:"custom_id"`
+		Content  string                    `json:"content"`
+		Embeds   []*discordgo.MessageEmbed `json:"embeds"`
+		Flags    discordgo.MessageFlags    `json:"flags"`
+	} `json:"data"`
+}
+
+// newSyntheticClient returns a client whose REST calls are answered locally, the interaction responses
+// it sends are passed to onResp.
+func newSyntheticClient(t *testing.T, onResp func(resp syntheticResponse)) *disc.Client {
+	c, err := disc.NewClient("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Sess().Client = &http.Client{
+		Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(r.URL.Path, "/callback") && onResp != nil {
+				var resp syntheticResponse
+				if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+					return nil, err
+				}
+				onResp(resp)
+			}
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       io.NopCloser(strings.NewReader("")),
+				Header:     http.Header{},
+				Request:    r,
+			}, nil
+		}),
+	}
+	return c
+}
+
+func syntheticClick(c *disc.Client, customID string, userID string, values ...string) disc.MsgComponentHandlerData {
+	i := &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:    "interaction",
+			AppID: "app",
+			Token: "token",
+			Type:  discordgo.InteractionMessageComponent,
+			Data: discordgo.MessageComponentInteractionData{
+				CustomID:      customID,
+				ComponentType: discordgo.ButtonComponent,
+				Values:        values,
+			},
+			User: &discordgo.User{ID: userID},
+		},
+	}
+	return disc.MsgComponentHandlerData{C: c, S: c.Sess(), I: i, Data: i.MessageComponentData()}
+}
+
+func handlerBySuffix(handlers map[string]disc.MsgComponentHandler, suffix string) (string, disc.MsgComponentHandler) {
+	for id, handler := range handlers {
+		if strings.HasSuffix(id, suffix) {
+			return id, handler
+		}
+	}
+	return "", nil
+}
+
+func pageFooter(p *disc.TypedPaginator[string]) *discordgo.MessageEmbedFooter {
+	return &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("Page %d of %d", p.Page(), p.LastPage()),
+	}
+}
+
+func TestPaginatorConcurrentNavigation(t *testing.T) {
+	is := is.New(t)
+	list := make([]string, 20)
+	for i := 0; i < 20; i++ {
+		list[i] = fmt.Sprintf("String %d", i+1)
+	}
+	var mu sync.Mutex
+	footers := []string{}
+	c := newSyntheticClient(t, func(resp syntheticResponse) {
+		mu.Lock()
+		defer mu.Unlock()
+		footers = append(footers, resp.Data.Embeds[0].Footer.Text)
+	})
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems(list).
+		SetFooterFunc(pageFooter).
+		Build(5)
+	prevID, prev := handlerBySuffix(handlers, "-prev")
+	nextID, next := handlerBySuffix(handlers, "-next")
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			is.NoErr(next(syntheticClick(c, nextID, "user")))
+		}()
+		go func() {
+			defer wg.Done()
+			is.NoErr(prev(syntheticClick(c, prevID, "user")))
+		}()
+		go func() {
+			defer wg.Done()
+			p.SetItems(list)
+		}()
+		go func() {
+			defer wg.Done()
+			p.Response()
+		}()
+	}
+	wg.Wait()
+
+	is.True(p.Page() >= 1 && p.Page() <= 4)
+	is.Equal(len(footers), 100)
+	for _, footer := range footers {
+		var page, last int
+		_, err := fmt.Sscanf(footer, "Page %d of %d", &page, &last)
+		is.NoErr(err)
+		is.True(page >= 1 && page <= last)
+	}
+}
+
+func TestPaginatorConcurrentSearch(t *testing.T) {
+	is := is.New(t)
+	c := newSyntheticClient(t, nil)
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems([]string{"String 1", "String 2"}).
+		SetFilterFunc(func(item string, query string) bool {
+			return strings.Contains(item, query)
+		}).
+		Build(1)
+	searchID, search := handlerBySuffix(handlers, "-search")
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			is.NoErr(search(syntheticClick(c, searchID, "user")))
+		}()
+		go func() {
+			defer wg.Done()
+			p.SetQuery(strconv.Itoa(i % 3))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPaginatorOutOfRangeNavigation(t *testing.T) {
+	is := is.New(t)
+	footers := []string{}
+	c := newSyntheticClient(t, func(resp syntheticResponse) {
+		footers = append(footers, resp.Data.Embeds[0].Footer.Text)
+	})
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems([]string{"String 1", "String 2"}).
+		SetFooterFunc(pageFooter).
+		UsePageSelect().
+		Build(1)
+	prevID, prev := handlerBySuffix(handlers, "-prev")
+	nextID, next := handlerBySuffix(handlers, "-next")
+	selectID, selectPage := handlerBySuffix(handlers, "-select")
+
+	is.NoErr(prev(syntheticClick(c, prevID, "user")))
+	is.NoErr(prev(syntheticClick(c, prevID, "user")))
+	is.Equal(p.Page(), 1)
+	is.NoErr(next(syntheticClick(c, nextID, "user")))
+	is.NoErr(next(syntheticClick(c, nextID, "user")))
+	is.Equal(p.Page(), 2)
+	is.NoErr(selectPage(syntheticClick(c, selectID, "user", "5")))
+	is.Equal(p.Page(), 2)
+	is.NoErr(selectPage(syntheticClick(c, selectID, "user", "1")))
+	is.Equal(p.Page(), 1)
+	is.Equal(footers, []string{"Page 1 of 2", "Page 1 of 2", "Page 2 of 2", "Page 2 of 2", "Page 2 of 2", "Page 1 of 2"})
+}
+
+func TestPaginatorOwnerLock(t *testing.T) {
+	is := is.New(t)
+	resps := []syntheticResponse{}
+	c := newSyntheticClient(t, func(resp syntheticResponse) {
+		resps = append(resps, resp)
+	})
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems([]string{"String 1", "String 2"}).
+		SetOwnerID("owner").
+		Build(1)
+	nextID, next := handlerBySuffix(handlers, "-next")
+
+	is.NoErr(next(syntheticClick(c, nextID, "someone")))
+	is.Equal(p.Page(), 1)
+	is.Equal(resps[0].Type, discordgo.InteractionResponseChannelMessageWithSource)
+	is.Equal(resps[0].Data.Flags, discordgo.MessageFlagsEphemeral)
+	is.NoErr(next(syntheticClick(c, nextID, "owner")))
+	is.Equal(p.Page(), 2)
+	is.Equal(resps[1].Type, discordgo.InteractionResponseUpdateMessage)
+
+	// sent with Response, the first user to interact owns it
+	p, handlers = disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems([]string{"String 1", "String 2"}).
+		UseOwnerLock().
+		Build(1)
+	nextID, next = handlerBySuffix(handlers, "-next")
+	is.NoErr(next(syntheticClick(c, nextID, "first")))
+	is.Equal(p.OwnerID(), "first")
+	is.Equal(p.Page(), 2)
+	prevID, prev := handlerBySuffix(handlers, "-prev")
+	is.NoErr(prev(syntheticClick(c, prevID, "someone")))
+	is.Equal(p.Page(), 2)
+	is.Equal(resps[3].Data.Flags, discordgo.MessageFlagsEphemeral)
+}
+
+func TestPaginatorErrPolicies(t *testing.T) {
+	errBoom := errors.New("boom")
+	tests := []struct {
+		name     string
+		policy   disc.PaginatorErrPolicy
+		respType discordgo.InteractionResponseType
+		flags    discordgo.MessageFlags
+		err      bool
+	}{
+		{"update", disc.PaginatorErrPolicyUpdate, discordgo.InteractionResponseUpdateMessage, 0, false},
+		{"ephemeral", disc.PaginatorErrPolicyEphemeral, discordgo.InteractionResponseChannelMessageWithSource, discordgo.MessageFlagsEphemeral, false},
+		{"propagate", disc.PaginatorErrPolicyPropagate, discordgo.InteractionResponseDeferredMessageUpdate, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			resps := []syntheticResponse{}
+			c := newSyntheticClient(t, func(resp syntheticResponse) {
+				resps = append(resps, resp)
+			})
+			p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+				SetInitialItems([]string{"String 1", "String 2"}).
+				SetBeforeNavigate(func(p *disc.TypedPaginator[string], page int) error {
+					return errBoom
+				}).
+				SetErrResp(&discordgo.InteractionResponseData{Content: "failed"}).
+				SetErrPolicy(tt.policy).
+				Build(1)
+			nextID, next := handlerBySuffix(handlers, "-next")
+
+			err := next(syntheticClick(c, nextID, "user"))
+			is.Equal(err != nil, tt.err)
+			if tt.err {
+				var perr *disc.PaginatorError
+				is.True(errors.As(err, &perr))
+				is.Equal(perr.Stage, disc.PaginatorStageBeforeNavigate)
+				is.True(errors.Is(err, errBoom))
+			}
+			is.Equal(p.Page(), 1)
+			is.Equal(len(resps), 1)
+			is.Equal(resps[0].Type, tt.respType)
+			is.Equal(resps[0].Data.Flags, tt.flags)
+			if tt.respType != discordgo.InteractionResponseDeferredMessageUpdate {
+				is.Equal(resps[0].Data.Content, "failed")
+			}
+		})
+	}
+}
+
+func TestPaginatorRenderErr(t *testing.T) {
+	is := is.New(t)
+	resps := []syntheticResponse{}
+	c := newSyntheticClient(t, func(resp syntheticResponse) {
+		resps = append(resps, resp)
+	})
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems([]string{"String 1", "String 2"}).
+		SetBeforeRender(func(p *disc.TypedPaginator[string]) error {
+			if p.Page() == 2 {
+				return errors.New("boom")
+			}
+			return nil
+		}).
+		Build(1)
+	nextID, next := handlerBySuffix(handlers, "-next")
+
+	is.NoErr(next(syntheticClick(c, nextID, "user")))
+	is.Equal(p.Page(), 1)
+	is.Equal(resps[0].Type, discordgo.InteractionResponseUpdateMessage)
+	is.Equal(resps[0].Data.Content, "Something went wrong while loading this page.")
+
+	// a failing hook without any error response must not render recursively
+	p, _ = disc.NewTypedPaginatorBuilder[string](c).
+		SetOnResp(func(p *disc.TypedPaginator[string]) error {
+			return errors.New("boom")
+		}).
+		Build(1)
+	is.Equal(p.UpdateResponse().Data.Content, "Something went wrong while loading this page.")
+	_, err := p.Render()
+	var perr *disc.PaginatorError
+	is.True(errors.As(err, &perr))
+	is.Equal(perr.Stage, disc.PaginatorStageRender)
+}
+
+func TestPaginatorAfterNavigateErr(t *testing.T) {
+	is := is.New(t)
+	c := newSyntheticClient(t, nil)
+	p, handlers := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems([]string{"String 1", "String 2"}).
+		SetAfterNavigate(func(p *disc.TypedPaginator[string]) error {
+			return errors.New("boom")
+		}).
+		SetErrPolicy(disc.PaginatorErrPolicyPropagate).
+		Build(1)
+	nextID, next := handlerBySuffix(handlers, "-next")
+
+	err := next(syntheticClick(c, nextID, "user"))
+	var perr *disc.PaginatorError
+	is.True(errors.As(err, &perr))
+	is.Equal(perr.Stage, disc.PaginatorStageAfterNavigate)
+	is.Equal(p.Page(), 2)
+}
+
+func TestPaginatorAutoSplit(t *testing.T) {
+	is := is.New(t)
+	c, err := disc.NewClient("", "")
+	is.NoErr(err)
+	list := []string{strings.Repeat("a", 3000), strings.Repeat("b", 1000), strings.Repeat("c", 1000), "d", strings.Repeat("e", 4000)}
+	p, _ := disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems(list).
+		SetDescFunc(func(p *disc.TypedPaginator[string]) string {
+			return strings.Join(p.CurPageItems(), "")
+		}).
+		SetAutoSplit(func(item string) int {
+			return len(item)
+		}, disc.LimitEmbedDescription).
+		Build(3)
+	is.Equal(p.LastPage(), 3)
+	is.Equal(p.CurPageItems(), list[:2])
+	_, err = p.Render()
+	is.NoErr(err)
+
+	p, _ = disc.NewTypedPaginatorBuilder[string](c).
+		SetInitialItems(list).
+		SetDescFunc(func(p *disc.TypedPaginator[string]) string {
+			return strings.Join(p.CurPageItems(), "")
+		}).
+		Build(3)
+	_, err = p.Render()
+	var perr *disc.PaginatorError
+	is.True(errors.As(err, &perr))
+	is.Equal(perr.Stage, disc.PaginatorStageValidate)
+	var limitErr *disc.LimitError
+	is.True(errors.As(err, &limitErr))
+	is.Equal(limitErr.Path, "embeds[0].description")
+}
@@ -1,18 +1,38 @@
 package webhooker
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/rest"
-	disgo_webhook "github.com/disgoorg/disgo/webhook"
+	"github.com/go-resty/resty/v2"
 	"github.com/stevo-go-utils/structures"
 )
 
 type Client struct {
 	recChMap *structures.SafeMap[string, *webhooker]
-	asyncCh  chan asyncSendMsg
+	asyncCh  chan sendMsg
+	// mu guards closed, sends register in senders under it so the queues are only closed once every send
+	// queueing a message returned.
+	mu      sync.RWMutex
+	closed  bool
+	senders sync.WaitGroup
+	// closing is closed by Close to abort sends blocked on a full queue.
+	closing chan struct{}
+	// webhookersMu serializes starting the webhooker of a new URL.
+	webhookersMu sync.Mutex
+	pending      *pendingCounter
+	workers      sync.WaitGroup
+	wal          *wal
+	rc           *resty.Client
+	// replaying is closed once the messages of the durable queue were queued again and left their queues,
+	// nil without one.
+	replaying chan struct{}
 	*ClientOpts
 }
 
@@ -24,6 +44,12 @@ type ClientOpts struct {
 	enableLogging       bool
 	logger              *slog.Logger
 	async               bool
+	rateLimiter         *RateLimiter
+	queueSize           int
+	queuePolicy         QueuePolicy
+	httpClient          *http.Client
+	transport           http.RoundTripper
+	baseURL             string
 }
 
 type ClientOptFunc func(opts *ClientOpts)
@@ -35,6 +61,8 @@ func DefaultClientOpts() *ClientOpts {
 		errDelay:            time.Second * 2,
 		enableLogging:       false,
 		logger:              slog.New(slog.Default().Handler()),
+		rateLimiter:         NewRateLimiter(),
+		baseURL:             DefaultBaseURL,
 	}
 }
 
@@ -80,6 +108,46 @@ func AsyncClientOpt() ClientOptFunc {
 	}
 }
 
+// RateLimiterClientOpt shares the rate limit state of the limiter, e.g. between clients sending to the same
+// webhooks.
+func RateLimiterClientOpt(rl *RateLimiter) ClientOptFunc {
+	return func(co *ClientOpts) {
+		co.rateLimiter = rl
+	}
+}
+
+// QueueClientOpt buffers up to size messages per webhook, policy decides what happens to sends when a queue
+// is full. In async mode the shared queue is bounded the same way. By default queues are unbuffered and
+// sends block.
+func QueueClientOpt(size int, policy QueuePolicy) ClientOptFunc {
+	return func(co *ClientOpts) {
+		co.queueSize = size
+		co.queuePolicy = policy
+	}
+}
+
+// HTTPClientClientOpt sends the webhooks with the client, e.g. to set timeouts, proxies or connection pooling.
+func HTTPClientClientOpt(hc *http.Client) ClientOptFunc {
+	return func(co *ClientOpts) {
+		co.httpClient = hc
+	}
+}
+
+// TransportClientOpt sends the webhooks with the transport, on top of the client of HTTPClientClientOpt when
+// both are given.
+func TransportClientOpt(transport http.RoundTripper) ClientOptFunc {
+	return func(co *ClientOpts) {
+		co.transport = transport
+	}
+}
+
+// BaseURLClientOpt sends the webhooks to the base URL instead of DefaultBaseURL, e.g. a local fake of the API.
+func BaseURLClientOpt(baseURL string) ClientOptFunc {
+	return func(co *ClientOpts) {
+		co.baseURL = baseURL
+	}
+}
+
 func NewClient(opts ...ClientOptFunc) (c *Client) {
 	o := DefaultClientOpts()
 	for _, opt := range opts {
@@ -87,12 +155,17 @@ func NewClient(opts ...ClientOptFunc) (c *Client) {
 	}
 	c = &Client{
 		recChMap:   structures.NewSafeMap[string, *webhooker](),
-		asyncCh:    make(chan asyncSendMsg),
+		asyncCh:    make(chan sendMsg, o.queueSize),
+		pending:    newPendingCounter(),
+		closing:    make(chan struct{}),
+		rc:         resty.NewWithClient(newHTTPClient(o.httpClient, o.transport)),
 		ClientOpts: o,
 	}
+	c.workers.Add(1)
 	go func() {
+		defer c.workers.Done()
 		for msg := range c.asyncCh {
-			msg.w.send(sendMsg{msg: msg.msg, opts: msg.opts})
+			msg.w.deliver(msg)
 		}
 	}()
 	return
@@ -100,6 +173,15 @@ func NewClient(opts ...ClientOptFunc) (c *Client) {
 
 type SendOpts struct {
 	RequestOpts []rest.RequestOpt
+	// Callback is called with the created message or the final error once the message was delivered.
+	Callback DeliveryCallback
+	// Context aborts the delivery, its requests and rate limit waits, once done. It also bounds how long the
+	// send blocks on a full queue with QueuePolicyBlockContext.
+	Context context.Context
+	// ThreadID targets the thread of the webhook's channel.
+	ThreadID string
+	// ThreadName creates a forum post with the name, only used by sends.
+	ThreadName string
 }
 
 type SendOptsFunc func(opts *SendOpts)
@@ -114,37 +196,225 @@ func SendRequestOpts(opts ...rest.RequestOpt) SendOptsFunc {
 	}
 }
 
-type sendMsg struct {
-	msg  discord.WebhookMessageCreate
-	opts *SendOpts
+// CallbackSendOpt calls the callback with the created message or the final error of the send.
+func CallbackSendOpt(callback DeliveryCallback) SendOptsFunc {
+	return func(so *SendOpts) {
+		so.Callback = callback
+	}
 }
 
-type asyncSendMsg struct {
-	msg  discord.WebhookMessageCreate
-	opts *SendOpts
-	w    *webhooker
+// ContextSendOpt aborts the delivery once the context is done and bounds how long the send blocks on a full
+// queue with QueuePolicyBlockContext.
+func ContextSendOpt(ctx context.Context) SendOptsFunc {
+	return func(so *SendOpts) {
+		so.Context = ctx
+	}
+}
+
+// ThreadSendOpt sends, edits, fetches or deletes the message in the thread of the webhook's channel.
+func ThreadSendOpt(threadID string) SendOptsFunc {
+	return func(so *SendOpts) {
+		so.ThreadID = threadID
+	}
+}
+
+// ThreadNameSendOpt creates a forum post with the name starting with the sent message, the webhook must
+// belong to a forum channel.
+func ThreadNameSendOpt(name string) SendOptsFunc {
+	return func(so *SendOpts) {
+		so.ThreadName = name
+	}
+}
+
+type sendMsg struct {
+	// method is the HTTP method of the request, POST executing the webhook when empty.
+	method string
+	// msgID is the message edited, fetched or deleted.
+	msgID       string
+	body        []byte
+	contentType string
+	opts        *SendOpts
+	delivery    *Delivery
+	w           *webhooker
+	// walID is the ID of the message in the durable queue, zero without one.
+	walID uint64
+	// replayed is done once the message replayed from the durable queue left its queue, nil for sends.
+	replayed *sync.WaitGroup
 }
 
+// Send queues the message for the webhook. Only parse errors are returned, use SendWithDelivery or
+// CallbackSendOpt for the outcome of the delivery.
 func (c *Client) Send(webhookURL string, msg discord.WebhookMessageCreate, opts ...SendOptsFunc) (err error) {
-	w, ok := c.recChMap.Get(webhookURL)
-	if !ok {
-		c.recChMap.Set(webhookURL, w)
-		wc, err := disgo_webhook.NewWithURL(webhookURL)
-		if err != nil {
-			return err
+	return c.send(webhookURL, msg, nil, opts...)
+}
+
+// SendContext is Send with ContextSendOpt(ctx).
+func (c *Client) SendContext(ctx context.Context, webhookURL string, msg discord.WebhookMessageCreate, opts ...SendOptsFunc) (err error) {
+	return c.send(webhookURL, msg, nil, append(opts, ContextSendOpt(ctx))...)
+}
+
+// SendWithDelivery queues the message for the webhook and returns a Delivery resolving to the created
+// message or the final error.
+func (c *Client) SendWithDelivery(webhookURL string, msg discord.WebhookMessageCreate, opts ...SendOptsFunc) (d *Delivery, err error) {
+	d = newDelivery()
+	err = c.send(webhookURL, msg, d, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (c *Client) send(webhookURL string, msg discord.WebhookMessageCreate, d *Delivery, opts ...SendOptsFunc) (err error) {
+	o := DefaultSendOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.ThreadName != "" {
+		msg.ThreadName = o.ThreadName
+	}
+	body, contentType, err := ParseWebhook(msg)
+	if err != nil {
+		if c.enableLogging {
+			c.logger.Error("failed to parse webhook", slog.Any("err", err))
 		}
-		w = c.newWebhooker(wc.URL())
-		c.recChMap.Set(webhookURL, w)
-		go w.listen()
+		return err
+	}
+	if c.replaying != nil {
+		<-c.replaying
+	}
+	return c.queue(webhookURL, sendMsg{body: body, contentType: contentType, opts: o, delivery: d})
+}
+
+// Edit queues the edit of a message sent by the webhook, after the messages queued before it. Only parse
+// errors are returned, use EditWithDelivery or CallbackSendOpt for the outcome.
+func (c *Client) Edit(webhookURL string, msgID string, msg discord.WebhookMessageUpdate, opts ...SendOptsFunc) (err error) {
+	return c.edit(webhookURL, msgID, msg, nil, opts...)
+}
+
+// EditWithDelivery queues the edit and returns a Delivery resolving to the edited message or the final
+// error.
+func (c *Client) EditWithDelivery(webhookURL string, msgID string, msg discord.WebhookMessageUpdate, opts ...SendOptsFunc) (d *Delivery, err error) {
+	d = newDelivery()
+	err = c.edit(webhookURL, msgID, msg, d, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (c *Client) edit(webhookURL string, msgID string, msg discord.WebhookMessageUpdate, d *Delivery, opts ...SendOptsFunc) (err error) {
+	o := DefaultSendOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+	body, contentType, err := webhookBody(msg, ContentTypeJSON)
+	if err != nil {
+		if c.enableLogging {
+			c.logger.Error("failed to parse webhook edit", slog.Any("err", err))
+		}
+		return err
+	}
+	if c.replaying != nil {
+		<-c.replaying
+	}
+	return c.queue(webhookURL, sendMsg{method: http.MethodPatch, msgID: msgID, body: body, contentType: string(contentType), opts: o, delivery: d})
+}
+
+// Delete queues the deletion of a message sent by the webhook, after the messages queued before it. Use
+// DeleteWithDelivery or CallbackSendOpt for the outcome.
+func (c *Client) Delete(webhookURL string, msgID string, opts ...SendOptsFunc) (err error) {
+	return c.delete(webhookURL, msgID, nil, opts...)
+}
+
+// DeleteWithDelivery queues the deletion and returns a Delivery resolving once the message was deleted or
+// to the final error.
+func (c *Client) DeleteWithDelivery(webhookURL string, msgID string, opts ...SendOptsFunc) (d *Delivery, err error) {
+	d = newDelivery()
+	err = c.delete(webhookURL, msgID, d, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return d, nil
+}
+
+func (c *Client) delete(webhookURL string, msgID string, d *Delivery, opts ...SendOptsFunc) (err error) {
+	o := DefaultSendOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+	if c.replaying != nil {
+		<-c.replaying
+	}
+	return c.queue(webhookURL, sendMsg{method: http.MethodDelete, msgID: msgID, opts: o, delivery: d})
+}
+
+// Get fetches a message sent by the webhook right away, sharing the rate limits and retries of the queued
+// messages.
+func (c *Client) Get(webhookURL string, msgID string, opts ...SendOptsFunc) (m *discord.Message, err error) {
 	o := DefaultSendOpts()
 	for _, opt := range opts {
 		opt(o)
 	}
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return nil, ErrClientClosed
+	}
+	w, err := c.webhooker(webhookURL)
+	c.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return w.send(sendMsg{method: http.MethodGet, msgID: msgID, opts: o})
+}
+
+// queue records the message in the durable queue unless it is replayed from it and queues it for its
+// webhook.
+func (c *Client) queue(webhookURL string, msg sendMsg) (err error) {
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return ErrClientClosed
+	}
+	c.senders.Add(1)
+	c.mu.RUnlock()
+	defer c.senders.Done()
+	w, err := c.webhooker(webhookURL)
+	if err != nil {
+		if c.wal != nil && msg.walID != 0 {
+			// a replayed message with an invalid URL will never be delivered
+			return errors.Join(err, c.wal.ack(msg.walID))
+		}
+		return err
+	}
+	if c.wal != nil && msg.walID == 0 {
+		msg.walID, err = c.wal.append(walEntry{
+			URL:         webhookURL,
+			Method:      msg.method,
+			MessageID:   msg.msgID,
+			ThreadID:    msg.opts.ThreadID,
+			ContentType: msg.contentType,
+			Body:        msg.body,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	msg.w = w
+	// replayed messages were accepted before the restart, they wait for room whatever the policy and stay in
+	// the durable queue when they could not be queued
+	policy := c.queuePolicy
+	if msg.replayed != nil {
+		policy = QueuePolicyBlock
+	}
+	c.pending.add()
 	if c.async {
-		c.asyncCh <- asyncSendMsg{msg: msg, opts: o, w: w}
+		err = c.enqueue(c.asyncCh, msg, policy)
 	} else {
-		w.ch <- sendMsg{msg: msg, opts: o}
+		err = c.enqueue(w.ch, msg, policy)
 	}
-	return
+	if err != nil && msg.replayed == nil {
+		w.ack(msg)
+	}
+	return err
 }
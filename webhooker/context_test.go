@@ -0,0 +1,60 @@
+package webhooker_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/matryer/is"
+	"github.com/stevo-go-utils/disc/webhooker"
+)
+
+func TestWebhookContext(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body) // lets the server notice the client going away
+		switch {
+		case r.URL.Path == "/slow":
+			<-r.Context().Done()
+		case r.URL.Path == "/limited":
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"message": "You are being rate limited.", "retry_after": 10}`)
+		case r.Method == http.MethodPatch:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			io.WriteString(w, `{"id": "1", "content": "test"}`)
+		}
+	}))
+	defer srv.Close()
+
+	m, err := webhooker.SendWithWaitContext(context.Background(), srv.URL+"/webhook", discord.WebhookMessageCreate{Content: "test"})
+	is.NoErr(err)
+	is.Equal(m.Content, "test")
+	is.NoErr(webhooker.EditContext(context.Background(), srv.URL+"/webhook", "1", `{"content": "edit"}`))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	start := time.Now()
+	err = webhooker.SendContext(ctx, srv.URL+"/slow", "{}")
+	is.True(errors.Is(err, context.DeadlineExceeded)) // in-flight request aborted
+	err = webhooker.SendContext(ctx, srv.URL+"/limited", "{}", webhooker.RateLimitWebhookOpt(1, 0))
+	is.True(errors.Is(err, context.DeadlineExceeded)) // rate limit wait aborted
+	is.True(time.Since(start) < time.Second)
+}
+
+func TestClientSendContext(t *testing.T) {
+	is := is.New(t)
+	c := newQueueTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d, err := c.SendWithDelivery(queueTestURL, discord.WebhookMessageCreate{Content: "a"}, webhooker.ContextSendOpt(ctx))
+	is.NoErr(err)
+	_, err = d.Wait(context.Background())
+	is.Equal(err, context.Canceled)
+	is.NoErr(c.Close(context.Background()))
+}
@@ -0,0 +1,66 @@
+package webhooker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+var ErrRetriesExhausted = errors.New("webhook retries exhausted")
+
+type DeliveryCallback func(m *discord.Message, err error)
+
+// Delivery resolves to the message created by a send or its final error.
+type Delivery struct {
+	done chan struct{}
+	m    *discord.Message
+	err  error
+}
+
+func newDelivery() *Delivery {
+	return &Delivery{
+		done: make(chan struct{}),
+	}
+}
+
+func (d *Delivery) resolve(m *discord.Message, err error) {
+	d.m = m
+	d.err = err
+	close(d.done)
+}
+
+// Done is closed once the delivery resolved.
+func (d *Delivery) Done() <-chan struct{} {
+	return d.done
+}
+
+// Wait blocks until the delivery resolved or the context is done.
+func (d *Delivery) Wait(ctx context.Context) (m *discord.Message, err error) {
+	select {
+	case <-d.done:
+		return d.m, d.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Message returns the created message, nil until the delivery resolved successfully.
+func (d *Delivery) Message() *discord.Message {
+	select {
+	case <-d.done:
+		return d.m
+	default:
+		return nil
+	}
+}
+
+// Err returns the final error of the delivery, nil until it resolved.
+func (d *Delivery) Err() error {
+	select {
+	case <-d.done:
+		return d.err
+	default:
+		return nil
+	}
+}
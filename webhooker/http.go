@@ -0,0 +1,43 @@
+package webhooker
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL is the Discord API base webhook URLs are sent to.
+const DefaultBaseURL = "https://discord.com/api"
+
+// newHTTPClient returns a copy of the client with the transport replaced when one is given, so resty setting
+// its default transport does not modify the given client.
+func newHTTPClient(hc *http.Client, transport http.RoundTripper) *http.Client {
+	c := &http.Client{}
+	if hc != nil {
+		*c = *hc
+	}
+	if transport != nil {
+		c.Transport = transport
+	}
+	return c
+}
+
+// rewriteBaseURL replaces everything before the /webhooks/ part of the webhook URL with the base URL.
+func rewriteBaseURL(webhookURL string, baseURL string) string {
+	if baseURL == "" || baseURL == DefaultBaseURL {
+		return webhookURL
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return webhookURL
+	}
+	i := strings.Index(u.Path, "/webhooks/")
+	if i == -1 {
+		return webhookURL
+	}
+	rewritten := strings.TrimSuffix(baseURL, "/") + u.Path[i:]
+	if u.RawQuery != "" {
+		rewritten += "?" + u.RawQuery
+	}
+	return rewritten
+}
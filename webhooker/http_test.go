@@ -0,0 +1,74 @@
+package webhooker_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/matryer/is"
+	"github.com/stevo-go-utils/disc/webhooker"
+)
+
+type roundTripFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClientBaseURL(t *testing.T) {
+	is := is.New(t)
+	paths := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.RequestURI()
+		io.WriteString(w, `{"id": "1", "content": "local"}`)
+	}))
+	defer srv.Close()
+	c := webhooker.NewClient(webhooker.BaseURLClientOpt(srv.URL + "/api"))
+
+	d, err := c.SendWithDelivery("https://discord.com/api/webhooks/1/token", discord.WebhookMessageCreate{Content: "local"})
+	is.NoErr(err)
+	m, err := d.Wait(context.Background())
+	is.NoErr(err)
+	is.Equal(m.Content, "local")
+	is.Equal(<-paths, "/api/webhooks/1/token?wait=true")
+	is.NoErr(c.Close(context.Background()))
+}
+
+func TestClientRateLimitHeaders(t *testing.T) {
+	is := is.New(t)
+	s := &discordLimitServer{limit: 2, window: time.Millisecond * 200}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	c := webhooker.NewClient(webhooker.BaseURLClientOpt(srv.URL+"/api"), webhooker.QueueClientOpt(10, webhooker.QueuePolicyBlock))
+
+	for i := 0; i < 5; i++ {
+		is.NoErr(c.Send("https://discord.com/api/webhooks/1/token", discord.WebhookMessageCreate{Content: "limited"}))
+	}
+	is.NoErr(c.Flush(context.Background()))
+	is.Equal(s.requests, 5)
+	is.Equal(s.tooMany, 0) // the client waited for the bucket to reset
+	is.NoErr(c.Close(context.Background()))
+}
+
+func TestTransportOpts(t *testing.T) {
+	is := is.New(t)
+	calls := atomic.Int32{}
+	transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		is.Equal(r.URL.Host, "discord.com")
+		return &http.Response{StatusCode: http.StatusNoContent, Body: io.NopCloser(http.NoBody), Header: http.Header{}, Request: r}, nil
+	})
+	is.NoErr(webhooker.Send("https://discord.com/api/webhooks/1/token", "{}", webhooker.TransportWebhookOpt(transport)))
+
+	hc := &http.Client{Timeout: time.Second}
+	c := webhooker.NewClient(webhooker.HTTPClientClientOpt(hc), webhooker.TransportClientOpt(transport))
+	is.NoErr(c.Send("https://discord.com/api/webhooks/1/token", discord.WebhookMessageCreate{Content: "fake"}))
+	is.NoErr(c.Close(context.Background()))
+	is.Equal(calls.Load(), int32(2))
+	is.Equal(hc.Transport, nil) // the given client is not modified
+}
@@ -0,0 +1,101 @@
+package webhooker
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrClientClosed = errors.New("webhooker client closed")
+
+// pendingCounter counts the queued messages that were not delivered yet.
+type pendingCounter struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{}
+}
+
+func newPendingCounter() *pendingCounter {
+	idle := make(chan struct{})
+	close(idle)
+	return &pendingCounter{idle: idle}
+}
+
+func (p *pendingCounter) add() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.n == 0 {
+		p.idle = make(chan struct{})
+	}
+	p.n++
+}
+
+func (p *pendingCounter) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.n--
+	if p.n == 0 {
+		close(p.idle)
+	}
+}
+
+func (p *pendingCounter) wait(ctx context.Context) error {
+	p.mu.Lock()
+	idle := p.idle
+	p.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Flush waits until every message queued so far, and replayed from a durable queue, was delivered or failed
+// for good.
+func (c *Client) Flush(ctx context.Context) error {
+	if c.replaying != nil {
+		select {
+		case <-c.replaying:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return c.pending.wait(ctx)
+}
+
+// Close stops accepting sends, delivers the queued messages and stops the goroutines of the client. When the
+// context is done first the queues keep draining in the background. Sends after Close, and sends still
+// blocked on a full queue, return ErrClientClosed.
+func (c *Client) Close(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.closing)
+		c.workers.Add(1)
+		go func() {
+			defer c.workers.Done()
+			// the queues are closed once no send can write to them anymore
+			c.senders.Wait()
+			close(c.asyncCh)
+			c.recChMap.ForEach(func(_ string, w *webhooker) {
+				close(w.ch)
+			})
+		}()
+	}
+	c.mu.Unlock()
+	stopped := make(chan struct{})
+	go func() {
+		c.workers.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if c.wal != nil {
+		return c.wal.close()
+	}
+	return nil
+}
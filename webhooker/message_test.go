@@ -0,0 +1,195 @@
+package webhooker_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/matryer/is"
+	"github.com/stevo-go-utils/disc/webhooker"
+	"github.com/stevo-go-utils/structures"
+)
+
+// fakeMessages emulates the webhook message routes, keeping the messages per thread.
+type fakeMessages struct {
+	mu       sync.Mutex
+	messages map[string]string
+	threads  map[string]string
+	requests []string
+	limited  bool
+}
+
+func newFakeMessages() *fakeMessages {
+	return &fakeMessages{messages: map[string]string{}, threads: map[string]string{}}
+}
+
+func (s *fakeMessages) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	if s.limited {
+		s.limited = false
+		w.Header().Set("Retry-After", "0.05")
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"message": "You are being rate limited.", "retry_after": 0.05}`)
+		return
+	}
+	thread := r.URL.Query().Get("thread_id")
+	_, msgID, _ := strings.Cut(r.URL.Path, "/messages/")
+	msg := struct {
+		Content    string `json:"content"`
+		ThreadName string `json:"thread_name"`
+	}{}
+	json.Unmarshal(body, &msg)
+	switch r.Method {
+	case http.MethodPost:
+		msgID = "1"
+		if msg.ThreadName != "" {
+			thread = "10"
+			s.threads[thread] = msg.ThreadName
+		}
+		s.messages[thread+"/"+msgID] = msg.Content
+	case http.MethodPatch:
+		if _, ok := s.messages[thread+"/"+msgID]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.messages[thread+"/"+msgID] = msg.Content
+	case http.MethodDelete:
+		delete(s.messages, thread+"/"+msgID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	content, ok := s.messages[thread+"/"+msgID]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if thread == "" {
+		thread = "2"
+	}
+	json.NewEncoder(w).Encode(map[string]string{"id": msgID, "channel_id": thread, "content": content})
+}
+
+func TestWebhookMessages(t *testing.T) {
+	is := is.New(t)
+	s := newFakeMessages()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	webhookURL := "https://discord.com/api/webhooks/1/token"
+	base := webhooker.BaseURLWebhookOpt(srv.URL + "/api")
+	retry := webhooker.RateLimitWebhookOpt(1, 0)
+
+	m, err := webhooker.SendWithWait(webhookURL, discord.WebhookMessageCreate{Content: "post"}, base, webhooker.ThreadNameWebhookOpt("forum post"))
+	is.NoErr(err)
+	is.Equal(m.ChannelID.String(), "10")
+	is.Equal(s.threads["10"], "forum post")
+
+	is.NoErr(webhooker.Send(webhookURL, "{\"content\": \"reply\"}", base, webhooker.ThreadWebhookOpt("10")))
+	m, err = webhooker.EditWithWait(webhookURL, "1", discord.WebhookMessageUpdate{Content: structures.Ptr("edited")}, base, webhooker.ThreadWebhookOpt("10"))
+	is.NoErr(err)
+	is.Equal(m.Content, "edited")
+
+	s.limited = true
+	m, err = webhooker.Get(webhookURL, "1", base, retry, webhooker.ThreadWebhookOpt("10"))
+	is.NoErr(err) // retried after the rate limit
+	is.Equal(m.Content, "edited")
+	is.NoErr(webhooker.Delete(webhookURL, "1", base, webhooker.ThreadWebhookOpt("10")))
+	_, err = webhooker.Get(webhookURL, "1", base, webhooker.ThreadWebhookOpt("10"))
+	is.True(err != nil) // deleted
+
+	_, err = webhooker.SendWithWait(webhookURL, "{}", webhooker.ThreadNameWebhookOpt("raw"))
+	is.True(err != nil) // raw payloads set thread_name themselves
+
+	is.Equal(s.requests, []string{
+		"POST /api/webhooks/1/token?wait=true",
+		"POST /api/webhooks/1/token?thread_id=10",
+		"PATCH /api/webhooks/1/token/messages/1?thread_id=10",
+		"GET /api/webhooks/1/token/messages/1?thread_id=10",
+		"GET /api/webhooks/1/token/messages/1?thread_id=10",
+		"DELETE /api/webhooks/1/token/messages/1?thread_id=10",
+		"GET /api/webhooks/1/token/messages/1?thread_id=10",
+	})
+}
+
+func TestClientMessages(t *testing.T) {
+	is := is.New(t)
+	s := newFakeMessages()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	webhookURL := "https://discord.com/api/webhooks/1/token"
+	c := webhooker.NewClient(webhooker.BaseURLClientOpt(srv.URL+"/api"), webhooker.QueueClientOpt(10, webhooker.QueuePolicyBlock))
+	ctx := context.Background()
+
+	d, err := c.SendWithDelivery(webhookURL, discord.WebhookMessageCreate{Content: "post"}, webhooker.ThreadNameSendOpt("forum post"))
+	is.NoErr(err)
+	m, err := d.Wait(ctx)
+	is.NoErr(err)
+	is.Equal(m.ChannelID.String(), "10")
+
+	// queued in order behind the send
+	is.NoErr(c.Send(webhookURL, discord.WebhookMessageCreate{Content: "reply"}, webhooker.ThreadSendOpt("10")))
+	d, err = c.EditWithDelivery(webhookURL, "1", discord.WebhookMessageUpdate{Content: structures.Ptr("edited")}, webhooker.ThreadSendOpt("10"))
+	is.NoErr(err)
+	m, err = d.Wait(ctx)
+	is.NoErr(err)
+	is.Equal(m.Content, "edited")
+
+	s.mu.Lock()
+	s.limited = true
+	s.mu.Unlock()
+	m, err = c.Get(webhookURL, "1", webhooker.ThreadSendOpt("10"))
+	is.NoErr(err) // retried after the rate limit
+	is.Equal(m.Content, "edited")
+
+	d, err = c.DeleteWithDelivery(webhookURL, "1", webhooker.ThreadSendOpt("10"))
+	is.NoErr(err)
+	m, err = d.Wait(ctx)
+	is.NoErr(err)
+	is.Equal(m, nil)
+	is.NoErr(c.Close(ctx))
+
+	_, err = c.Get(webhookURL, "1")
+	is.Equal(err, webhooker.ErrClientClosed)
+	is.Equal(s.requests, []string{
+		"POST /api/webhooks/1/token?wait=true",
+		"POST /api/webhooks/1/token?thread_id=10",
+		"PATCH /api/webhooks/1/token/messages/1?thread_id=10",
+		"GET /api/webhooks/1/token/messages/1?thread_id=10",
+		"GET /api/webhooks/1/token/messages/1?thread_id=10",
+		"DELETE /api/webhooks/1/token/messages/1?thread_id=10",
+	})
+}
+
+func TestDurableMessages(t *testing.T) {
+	is := is.New(t)
+	s := newFakeMessages()
+	s.messages["10/1"] = "post"
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	dir := t.TempDir()
+	// an edit and a deletion left in the log by a stopped process
+	wal := `{"op":"enqueue","id":1,"url":"https://discord.com/api/webhooks/1/token","method":"PATCH","message_id":"1","thread_id":"10","content_type":"application/json","body":"eyJjb250ZW50IjoiZWRpdGVkIn0="}
+{"op":"enqueue","id":2,"url":"https://discord.com/api/webhooks/1/token","method":"DELETE","message_id":"1","thread_id":"10"}
+`
+	is.NoErr(os.WriteFile(filepath.Join(dir, "webhooker.wal"), []byte(wal), 0o644))
+
+	c, err := webhooker.NewDurableClient(dir, webhooker.BaseURLClientOpt(srv.URL+"/api"))
+	is.NoErr(err)
+	is.NoErr(c.Flush(context.Background()))
+	is.NoErr(c.Close(context.Background()))
+	is.Equal(s.requests, []string{
+		"PATCH /api/webhooks/1/token/messages/1?thread_id=10",
+		"DELETE /api/webhooks/1/token/messages/1?thread_id=10",
+	})
+	is.Equal(len(s.messages), 0)
+}
@@ -0,0 +1,118 @@
+package webhooker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+var (
+	ErrQueueFull      = errors.New("webhook queue full")
+	ErrMessageDropped = errors.New("webhook message dropped from a full queue")
+)
+
+// QueuePolicy decides what a send does when the queue of its webhook is full.
+type QueuePolicy int
+
+const (
+	// QueuePolicyBlock blocks the send until the queue has room or the client is closed.
+	QueuePolicyBlock QueuePolicy = iota
+	// QueuePolicyBlockContext blocks the send until the queue has room or the context of ContextSendOpt is
+	// done, returning its error.
+	QueuePolicyBlockContext
+	// QueuePolicyDropNewest drops the message being sent.
+	QueuePolicyDropNewest
+	// QueuePolicyDropOldest drops the oldest queued message to make room.
+	QueuePolicyDropOldest
+	// QueuePolicyError returns ErrQueueFull from the send.
+	QueuePolicyError
+)
+
+// enqueue queues the message on the channel following the policy. Dropped messages resolve with
+// ErrMessageDropped, sends blocked when the client is closed return ErrClientClosed.
+func (c *Client) enqueue(ch chan sendMsg, msg sendMsg, policy QueuePolicy) error {
+	msg.w.queued.Add(1)
+	select {
+	case ch <- msg:
+		return nil
+	default:
+	}
+	switch policy {
+	case QueuePolicyBlockContext:
+		ctx := msg.opts.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		select {
+		case ch <- msg:
+			return nil
+		case <-ctx.Done():
+			msg.w.queued.Add(-1)
+			c.pending.done()
+			return ctx.Err()
+		case <-c.closing:
+			msg.w.queued.Add(-1)
+			c.pending.done()
+			return ErrClientClosed
+		}
+	case QueuePolicyDropNewest:
+		msg.w.drop(msg)
+		return nil
+	case QueuePolicyDropOldest:
+		for cap(ch) > 0 {
+			select {
+			case ch <- msg:
+				return nil
+			case old := <-ch:
+				old.w.drop(old)
+			}
+		}
+		msg.w.drop(msg)
+		return nil
+	case QueuePolicyError:
+		msg.w.queued.Add(-1)
+		c.pending.done()
+		return ErrQueueFull
+	}
+	select {
+	case ch <- msg:
+		return nil
+	case <-c.closing:
+		msg.w.queued.Add(-1)
+		c.pending.done()
+		return ErrClientClosed
+	}
+}
+
+func (w *webhooker) drop(msg sendMsg) {
+	w.queued.Add(-1)
+	defer w.pending.done()
+	w.ack(msg)
+	if w.enableLogging {
+		w.logger.Warn("dropped webhook from a full queue", slog.String("url", w.url))
+	}
+	if msg.delivery != nil {
+		msg.delivery.resolve(nil, ErrMessageDropped)
+	}
+	if msg.opts.Callback != nil {
+		msg.opts.Callback(nil, ErrMessageDropped)
+	}
+}
+
+// QueueDepth returns how many messages are queued for the webhook and not being sent yet.
+func (c *Client) QueueDepth(webhookURL string) int {
+	w, ok := c.recChMap.Get(webhookURL)
+	if !ok {
+		return 0
+	}
+	return int(w.queued.Load())
+}
+
+// QueueDepths returns the queue depth of every webhook the client sent to.
+func (c *Client) QueueDepths() map[string]int {
+	depths := map[string]int{}
+	c.recChMap.ForEach(func(webhookURL string, w *webhooker) {
+		depths[webhookURL] = int(w.queued.Load())
+	})
+	return depths
+}
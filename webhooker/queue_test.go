@@ -0,0 +1,112 @@
+package webhooker_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/matryer/is"
+	"github.com/stevo-go-utils/disc/webhooker"
+)
+
+const queueTestURL = "https://discord.com/api/webhooks/1/token"
+
+// newQueueTestClient returns a client sending to a local server answering every webhook with a message.
+func newQueueTestClient(t *testing.T, opts ...webhooker.ClientOptFunc) *webhooker.Client {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		io.WriteString(w, `{"id": "1"}`)
+	}))
+	t.Cleanup(srv.Close)
+	return webhooker.NewClient(append(opts, webhooker.BaseURLClientOpt(srv.URL+"/api"))...)
+}
+
+// blockWorker sends a message whose callback holds the webhook's worker until release is closed.
+func blockWorker(t *testing.T, c *webhooker.Client, release chan struct{}) {
+	started := make(chan struct{})
+	err := c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "first"}, webhooker.CallbackSendOpt(func(*discord.Message, error) {
+		close(started)
+		<-release
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+}
+
+func TestQueuePolicyError(t *testing.T) {
+	is := is.New(t)
+	c := newQueueTestClient(t, webhooker.QueueClientOpt(2, webhooker.QueuePolicyError))
+	release := make(chan struct{})
+	blockWorker(t, c, release)
+
+	is.NoErr(c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "a"}))
+	is.NoErr(c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "b"}))
+	is.Equal(c.QueueDepth(queueTestURL), 2)
+	is.Equal(c.QueueDepths(), map[string]int{queueTestURL: 2})
+	is.Equal(c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "c"}), webhooker.ErrQueueFull)
+
+	close(release)
+	is.NoErr(c.Close(context.Background()))
+	is.Equal(c.QueueDepth(queueTestURL), 0)
+}
+
+func TestQueuePolicyDrop(t *testing.T) {
+	is := is.New(t)
+	c := newQueueTestClient(t, webhooker.QueueClientOpt(1, webhooker.QueuePolicyDropOldest))
+	release := make(chan struct{})
+	blockWorker(t, c, release)
+
+	oldest, err := c.SendWithDelivery(queueTestURL, discord.WebhookMessageCreate{Content: "a"})
+	is.NoErr(err)
+	_, err = c.SendWithDelivery(queueTestURL, discord.WebhookMessageCreate{Content: "b"})
+	is.NoErr(err)
+	_, err = oldest.Wait(context.Background())
+	is.Equal(err, webhooker.ErrMessageDropped)
+	is.Equal(c.QueueDepth(queueTestURL), 1)
+
+	close(release)
+	is.NoErr(c.Close(context.Background()))
+}
+
+func TestQueuePolicyBlockContext(t *testing.T) {
+	is := is.New(t)
+	c := newQueueTestClient(t, webhooker.QueueClientOpt(1, webhooker.QueuePolicyBlockContext))
+	release := make(chan struct{})
+	blockWorker(t, c, release)
+
+	is.NoErr(c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "a"}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	is.Equal(c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "b"}, webhooker.ContextSendOpt(ctx)), context.Canceled)
+	is.Equal(c.QueueDepth(queueTestURL), 1)
+
+	close(release)
+	is.NoErr(c.Flush(context.Background()))
+	is.NoErr(c.Close(context.Background()))
+}
+
+func TestQueuePolicyBlockClose(t *testing.T) {
+	is := is.New(t)
+	c := newQueueTestClient(t, webhooker.QueueClientOpt(1, webhooker.QueuePolicyBlock))
+	release := make(chan struct{})
+	blockWorker(t, c, release)
+
+	is.NoErr(c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "a"}))
+	blocked := make(chan error)
+	go func() {
+		blocked <- c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "b"})
+	}()
+	time.Sleep(time.Millisecond * 50)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+	defer cancel()
+	is.Equal(c.Close(ctx), context.DeadlineExceeded) // not held up by the blocked send
+	is.Equal(<-blocked, webhooker.ErrClientClosed)
+
+	close(release)
+	is.NoErr(c.Close(context.Background()))
+}
@@ -0,0 +1,129 @@
+package webhooker
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter tracks Discord's rate limit headers per bucket and the global limit. Routes are keyed by
+// their top-level resource, the webhook URL, since Discord scopes bucket hashes by it.
+type RateLimiter struct {
+	mu          sync.Mutex
+	globalReset time.Time
+	routes      map[string]string
+	buckets     map[string]*rateLimitBucket
+}
+
+type rateLimitBucket struct {
+	limit     int
+	remaining int
+	reset     time.Time
+}
+
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		routes:  map[string]string{},
+		buckets: map[string]*rateLimitBucket{},
+	}
+}
+
+// Wait blocks until a request on the route is allowed by the global limit and the route's bucket, then
+// reserves the request in the bucket.
+func (rl *RateLimiter) Wait(ctx context.Context, route string) error {
+	for {
+		delay := rl.reserve(route)
+		if delay <= 0 {
+			return nil
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (rl *RateLimiter) reserve(route string) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	if now.Before(rl.globalReset) {
+		return rl.globalReset.Sub(now)
+	}
+	b, ok := rl.buckets[rl.bucketKey(route)]
+	if !ok {
+		return 0
+	}
+	if !now.Before(b.reset) {
+		b.remaining = b.limit
+	}
+	if b.remaining <= 0 {
+		return b.reset.Sub(now)
+	}
+	b.remaining--
+	return 0
+}
+
+// Update records the rate limit headers of a response on the route. For a 429 it returns how long Discord
+// asked to wait, Wait already honors it.
+func (rl *RateLimiter) Update(route string, status int, header http.Header) (retryAfter time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	now := time.Now()
+	if hash := header.Get("X-RateLimit-Bucket"); hash != "" {
+		rl.routes[route] = hash
+	}
+	key := rl.bucketKey(route)
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &rateLimitBucket{}
+	}
+	known := false
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		b.limit = limit
+		known = true
+	}
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		b.remaining = remaining
+		known = true
+	}
+	if resetAfter, err := strconv.ParseFloat(header.Get("X-RateLimit-Reset-After"), 64); err == nil {
+		b.reset = now.Add(secondsDuration(resetAfter))
+		known = true
+	}
+	if status == http.StatusTooManyRequests {
+		if secs, err := strconv.ParseFloat(header.Get("Retry-After"), 64); err == nil {
+			retryAfter = secondsDuration(secs)
+		}
+		if strings.EqualFold(header.Get("X-RateLimit-Global"), "true") || header.Get("X-RateLimit-Scope") == "global" {
+			rl.globalReset = now.Add(retryAfter)
+			return retryAfter
+		}
+		if retryAfter > 0 {
+			b.remaining = 0
+			b.reset = now.Add(retryAfter)
+			known = true
+		}
+	}
+	if known {
+		rl.buckets[key] = b
+	}
+	return retryAfter
+}
+
+func (rl *RateLimiter) bucketKey(route string) string {
+	if hash, ok := rl.routes[route]; ok {
+		return hash + ":" + route
+	}
+	return route
+}
+
+func secondsDuration(secs float64) time.Duration {
+	return time.Duration(secs * float64(time.Second))
+}
@@ -0,0 +1,116 @@
+package webhooker_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/stevo-go-utils/disc/webhooker"
+)
+
+// discordLimitServer emulates Discord's rate limit headers with a bucket of limit requests per window.
+type discordLimitServer struct {
+	mu        sync.Mutex
+	limit     int
+	window    time.Duration
+	used      int
+	reset     time.Time
+	global    time.Time
+	requests  int
+	tooMany   int
+	forceGlob bool
+}
+
+func (s *discordLimitServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	now := time.Now()
+	if s.forceGlob {
+		s.forceGlob = false
+		s.global = now.Add(time.Millisecond * 200)
+		s.tooMany++
+		w.Header().Set("X-RateLimit-Global", "true")
+		w.Header().Set("Retry-After", "0.2")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	if now.Before(s.global) {
+		s.tooMany++
+		w.Header().Set("X-RateLimit-Global", "true")
+		w.Header().Set("Retry-After", strconv.FormatFloat(s.global.Sub(now).Seconds(), 'f', 3, 64))
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	if !now.Before(s.reset) {
+		s.used = 0
+		s.reset = now.Add(s.window)
+	}
+	resetAfter := strconv.FormatFloat(s.reset.Sub(now).Seconds(), 'f', 3, 64)
+	w.Header().Set("X-RateLimit-Bucket", "webhook")
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(s.limit))
+	w.Header().Set("X-RateLimit-Reset-After", resetAfter)
+	if s.used >= s.limit {
+		s.tooMany++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("Retry-After", resetAfter)
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	s.used++
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(s.limit-s.used))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *discordLimitServer) do(t *testing.T, rl *webhooker.RateLimiter, url string) int {
+	t.Helper()
+	if err := rl.Wait(context.Background(), url); err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.Post(url, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	rl.Update(url, res.StatusCode, res.Header)
+	return res.StatusCode
+}
+
+func TestRateLimiterBucket(t *testing.T) {
+	is := is.New(t)
+	s := &discordLimitServer{limit: 2, window: time.Millisecond * 200}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	rl := webhooker.NewRateLimiter()
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		is.Equal(s.do(t, rl, srv.URL+"/api/webhooks/1/a"), http.StatusNoContent)
+	}
+	is.Equal(s.tooMany, 0)                             // waited before exhausting the bucket
+	is.True(time.Since(start) >= time.Millisecond*400) // three windows of two requests
+}
+
+func TestRateLimiterGlobal(t *testing.T) {
+	is := is.New(t)
+	s := &discordLimitServer{limit: 100, window: time.Second, forceGlob: true}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	rl := webhooker.NewRateLimiter()
+
+	is.Equal(s.do(t, rl, srv.URL+"/api/webhooks/1/a"), http.StatusTooManyRequests)
+	start := time.Now()
+	is.Equal(s.do(t, rl, srv.URL+"/api/webhooks/2/b"), http.StatusNoContent) // the global limit is shared by all URLs
+	is.True(time.Since(start) >= time.Millisecond*150)
+	is.Equal(s.tooMany, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rl.Update(srv.URL+"/api/webhooks/1/a", http.StatusTooManyRequests, http.Header{"Retry-After": []string{"1"}})
+	is.Equal(rl.Wait(ctx, srv.URL+"/api/webhooks/1/a"), context.Canceled)
+}
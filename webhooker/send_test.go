@@ -1,7 +1,12 @@
 package webhooker_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -36,5 +41,56 @@ func TestSend(t *testing.T) {
 		err := c.Send(os.Getenv("WEBHOOK_URL"), discord.NewWebhookMessageCreateBuilder().SetContent(fmt.Sprint(i)).Build())
 		is.NoErr(err)
 	}
-	<-make(chan struct{})
+	is.NoErr(c.Flush(context.Background()))
+	is.NoErr(c.Close(context.Background()))
+}
+
+func TestSendWithDelivery(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		msg := discord.WebhookMessageCreate{}
+		json.NewDecoder(r.Body).Decode(&msg)
+		if msg.Content == "rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"message": "Cannot send an empty message", "code": 50006}`)
+			return
+		}
+		json.NewEncoder(w).Encode(discord.Message{ID: 1, Content: msg.Content})
+	}))
+	defer srv.Close()
+	c := webhooker.NewClient(webhooker.BaseURLClientOpt(srv.URL + "/api"))
+	d, err := c.SendWithDelivery(queueTestURL, discord.NewWebhookMessageCreateBuilder().SetContent("delivery").Build())
+	is.NoErr(err)
+	m, err := d.Wait(context.Background())
+	is.NoErr(err)
+	is.Equal(m.ID.String(), "1")
+	is.Equal(m.Content, "delivery")
+
+	type result struct {
+		m   *discord.Message
+		err error
+	}
+	results := make(chan result, 2)
+	callback := webhooker.CallbackSendOpt(func(m *discord.Message, err error) {
+		results <- result{m, err}
+	})
+	is.NoErr(c.Send(queueTestURL, discord.NewWebhookMessageCreateBuilder().SetContent("callback").Build(), callback))
+	r := <-results
+	is.NoErr(r.err)
+	is.Equal(r.m.Content, "callback")
+	is.NoErr(c.Send(queueTestURL, discord.NewWebhookMessageCreateBuilder().SetContent("rejected").Build(), callback))
+	r = <-results
+	is.True(r.err != nil)
+	is.Equal(r.m, nil)
+	is.NoErr(c.Close(context.Background()))
+}
+
+func TestClose(t *testing.T) {
+	is := is.New(t)
+	c := webhooker.NewClient(webhooker.AsyncClientOpt())
+	is.NoErr(c.Flush(context.Background()))
+	is.NoErr(c.Close(context.Background()))
+	is.NoErr(c.Close(context.Background()))
+	err := c.Send("https://discord.com/api/webhooks/1/token", discord.NewWebhookMessageCreateBuilder().SetContent("closed").Build())
+	is.Equal(err, webhooker.ErrClientClosed)
 }
@@ -0,0 +1,265 @@
+package webhooker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	walFileName = "webhooker.wal"
+	// walCompactThreshold is how many acknowledgements the log collects before it is rewritten with only the
+	// undelivered messages.
+	walCompactThreshold = 1000
+)
+
+const (
+	walOpEnqueue = "enqueue"
+	walOpAck     = "ack"
+)
+
+type walEntry struct {
+	Op          string `json:"op"`
+	ID          uint64 `json:"id"`
+	URL         string `json:"url,omitempty"`
+	Method      string `json:"method,omitempty"`
+	MessageID   string `json:"message_id,omitempty"`
+	ThreadID    string `json:"thread_id,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// wal is an append-only log of the queued messages, a message is acknowledged once it was delivered, failed
+// for good or dropped.
+type wal struct {
+	mu     sync.Mutex
+	path   string
+	f      *os.File
+	w      *bufio.Writer
+	nextID uint64
+	live   map[uint64]walEntry
+	acks   int
+}
+
+// openWAL opens the log in the directory and returns the messages that were not acknowledged, in the order
+// they were enqueued.
+func openWAL(dir string) (l *wal, pending []walEntry, err error) {
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return nil, nil, err
+	}
+	l = &wal{
+		path:   filepath.Join(dir, walFileName),
+		nextID: 1,
+		live:   map[uint64]walEntry{},
+	}
+	if err = l.read(); err != nil {
+		return nil, nil, err
+	}
+	if err = l.rewrite(); err != nil {
+		return nil, nil, err
+	}
+	for _, e := range l.live {
+		pending = append(pending, e)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ID < pending[j].ID
+	})
+	return l, pending, nil
+}
+
+func (l *wal) read() error {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var torn error
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			// only the last line can be torn, a bad line followed by more entries means the log is corrupt
+			if torn != nil {
+				return torn
+			}
+			e := walEntry{}
+			if jerr := json.Unmarshal(line, &e); jerr != nil {
+				torn = fmt.Errorf("webhooker: corrupt log entry: %w", jerr)
+			} else {
+				l.apply(e)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+func (l *wal) apply(e walEntry) {
+	switch e.Op {
+	case walOpEnqueue:
+		l.live[e.ID] = e
+	case walOpAck:
+		delete(l.live, e.ID)
+	}
+	if e.ID >= l.nextID {
+		l.nextID = e.ID + 1
+	}
+}
+
+// rewrite compacts the log to the messages not acknowledged yet.
+func (l *wal) rewrite() (err error) {
+	tmpPath := l.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint64, 0, len(l.live))
+	for id := range l.live {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, id := range ids {
+		if err = enc.Encode(l.live[id]); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err = w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if l.f != nil {
+		l.f.Close()
+	}
+	if err = os.Rename(tmpPath, l.path); err != nil {
+		return err
+	}
+	l.f, err = os.OpenFile(l.path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	l.w = bufio.NewWriter(l.f)
+	l.acks = 0
+	return nil
+}
+
+func (l *wal) write(e walEntry) error {
+	if err := json.NewEncoder(l.w).Encode(e); err != nil {
+		return err
+	}
+	if err := l.w.Flush(); err != nil {
+		return err
+	}
+	return l.f.Sync()
+}
+
+func (l *wal) append(e walEntry) (id uint64, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.Op = walOpEnqueue
+	e.ID = l.nextID
+	if err = l.write(e); err != nil {
+		return 0, err
+	}
+	l.nextID++
+	l.live[e.ID] = e
+	return e.ID, nil
+}
+
+func (l *wal) ack(id uint64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.live[id]; !ok {
+		return nil
+	}
+	if err := l.write(walEntry{Op: walOpAck, ID: id}); err != nil {
+		return err
+	}
+	delete(l.live, id)
+	l.acks++
+	if l.acks >= walCompactThreshold {
+		return l.rewrite()
+	}
+	return nil
+}
+
+func (l *wal) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.rewrite(); err != nil {
+		return err
+	}
+	return l.f.Close()
+}
+
+// NewDurableClient creates a client that records queued sends (attachments included), edits and deletions in
+// an append-only log in the directory. Messages not delivered before the process stopped are sent again in
+// order, whatever the queue policy. Sends wait until the replayed messages left their queues. The log holds the
+// webhook tokens and is only readable by the owner. A log corrupted before its last line is an error.
+func NewDurableClient(dir string, opts ...ClientOptFunc) (c *Client, err error) {
+	l, pending, err := openWAL(dir)
+	if err != nil {
+		return nil, err
+	}
+	c = NewClient(opts...)
+	c.wal = l
+	c.replaying = make(chan struct{})
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		defer close(c.replaying)
+		replayed := &sync.WaitGroup{}
+		for _, e := range pending {
+			o := DefaultSendOpts()
+			o.ThreadID = e.ThreadID
+			replayed.Add(1)
+			err := c.queue(e.URL, sendMsg{
+				method:      e.Method,
+				msgID:       e.MessageID,
+				body:        e.Body,
+				contentType: e.ContentType,
+				opts:        o,
+				walID:       e.ID,
+				replayed:    replayed,
+			})
+			if err != nil {
+				replayed.Done()
+			}
+			if errors.Is(err, ErrClientClosed) {
+				return
+			}
+			if err != nil && c.enableLogging {
+				c.logger.Error("failed to replay webhook", slog.Any("err", err))
+			}
+		}
+		// sends could drop the replayed messages from a full queue
+		replayed.Wait()
+	}()
+	return c, nil
+}
@@ -0,0 +1,174 @@
+package webhooker_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/matryer/is"
+	"github.com/stevo-go-utils/disc/webhooker"
+)
+
+func readWAL(t *testing.T, dir string) (entries []map[string]any) {
+	t.Helper()
+	f, err := os.Open(filepath.Join(dir, "webhooker.wal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		e := map[string]any{}
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// webhookRecorder is a local webhook server recording the content of the executed webhooks.
+type webhookRecorder struct {
+	mu       sync.Mutex
+	contents []string
+	srv      *httptest.Server
+}
+
+func newWebhookRecorder(t *testing.T) *webhookRecorder {
+	rec := &webhookRecorder{}
+	rec.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := []byte{}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			payload = []byte(r.FormValue("payload_json"))
+		} else {
+			payload, _ = io.ReadAll(r.Body)
+		}
+		msg := discord.WebhookMessageCreate{}
+		json.Unmarshal(payload, &msg)
+		rec.mu.Lock()
+		rec.contents = append(rec.contents, msg.Content)
+		rec.mu.Unlock()
+		io.WriteString(w, `{"id": "1"}`)
+	}))
+	t.Cleanup(rec.srv.Close)
+	return rec
+}
+
+func (rec *webhookRecorder) opt() webhooker.ClientOptFunc {
+	return webhooker.BaseURLClientOpt(rec.srv.URL + "/api")
+}
+
+func (rec *webhookRecorder) sent() []string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return slices.Clone(rec.contents)
+}
+
+func TestDurableQueue(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	before := newWebhookRecorder(t)
+	c, err := webhooker.NewDurableClient(dir, before.opt(), webhooker.QueueClientOpt(10, webhooker.QueuePolicyBlock))
+	is.NoErr(err)
+	release := make(chan struct{})
+	defer close(release)
+	blockWorker(t, c, release)
+	is.NoErr(c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "a"}))
+	is.NoErr(c.Send(queueTestURL, discord.WebhookMessageCreate{
+		Content: "b",
+		Files:   []*discord.File{{Name: "b.txt", Reader: bytes.NewReader([]byte("attachment"))}},
+	}))
+	// the process stops while the worker is still busy with the first message
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	is.Equal(c.Close(ctx), context.Canceled)
+
+	entries := readWAL(t, dir)
+	is.Equal(len(entries), 4) // the first message was acknowledged before its callback blocked
+	is.Equal(entries[1]["op"], "ack")
+	is.True(strings.HasPrefix(entries[3]["content_type"].(string), "multipart/form-data"))
+	is.Equal(before.sent(), []string{"first"})
+
+	after := newWebhookRecorder(t)
+	c, err = webhooker.NewDurableClient(dir, after.opt(), webhooker.QueueClientOpt(10, webhooker.QueuePolicyBlock))
+	is.NoErr(err)
+	is.NoErr(c.Flush(context.Background()))
+	is.NoErr(c.Close(context.Background()))
+	is.Equal(after.sent(), []string{"a", "b"})
+	is.Equal(len(readWAL(t, dir)), 0) // every replayed message was acknowledged and compacted away
+}
+
+func TestDurableQueuePolicy(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	wal := &bytes.Buffer{}
+	want := []string{}
+	for i := 1; i <= 10; i++ {
+		body, _ := json.Marshal(discord.WebhookMessageCreate{Content: strconv.Itoa(i)})
+		json.NewEncoder(wal).Encode(map[string]any{"op": "enqueue", "id": i, "url": queueTestURL, "content_type": "application/json", "body": body})
+		want = append(want, strconv.Itoa(i))
+	}
+	is.NoErr(os.WriteFile(filepath.Join(dir, "webhooker.wal"), wal.Bytes(), 0o644))
+
+	for _, policy := range []webhooker.QueuePolicy{webhooker.QueuePolicyError, webhooker.QueuePolicyDropOldest} {
+		rec := newWebhookRecorder(t)
+		c, err := webhooker.NewDurableClient(dir, rec.opt(), webhooker.QueueClientOpt(2, policy))
+		is.NoErr(err)
+		// sent while the log is replayed, it must not push replayed messages out of the full queue
+		is.NoErr(c.Send(queueTestURL, discord.WebhookMessageCreate{Content: "new"}))
+		is.NoErr(c.Flush(context.Background()))
+		is.NoErr(c.Close(context.Background()))
+		is.Equal(rec.sent(), append(slices.Clone(want), "new")) // nothing replayed was lost
+		is.Equal(len(readWAL(t, dir)), 0)
+		is.NoErr(os.WriteFile(filepath.Join(dir, "webhooker.wal"), wal.Bytes(), 0o644))
+	}
+}
+
+func TestDurableQueueLog(t *testing.T) {
+	is := is.New(t)
+	dir := filepath.Join(t.TempDir(), "wal")
+	entry := func(i int) string {
+		body, _ := json.Marshal(discord.WebhookMessageCreate{Content: strconv.Itoa(i)})
+		b, _ := json.Marshal(map[string]any{"op": "enqueue", "id": i, "url": queueTestURL, "content_type": "application/json", "body": body})
+		return string(b) + "\n"
+	}
+
+	// the log holds the webhook tokens
+	c, err := webhooker.NewDurableClient(dir)
+	is.NoErr(err)
+	is.NoErr(c.Close(context.Background()))
+	info, err := os.Stat(dir)
+	is.NoErr(err)
+	is.Equal(info.Mode().Perm(), os.FileMode(0o700))
+	info, err = os.Stat(filepath.Join(dir, "webhooker.wal"))
+	is.NoErr(err)
+	is.Equal(info.Mode().Perm(), os.FileMode(0o600))
+
+	// a torn last line is dropped
+	is.NoErr(os.WriteFile(filepath.Join(dir, "webhooker.wal"), []byte(entry(1)+`{"op": "enq`), 0o600))
+	rec := newWebhookRecorder(t)
+	c, err = webhooker.NewDurableClient(dir, rec.opt())
+	is.NoErr(err)
+	is.NoErr(c.Flush(context.Background()))
+	is.NoErr(c.Close(context.Background()))
+	is.Equal(rec.sent(), []string{"1"})
+
+	// a bad line followed by more entries is not
+	is.NoErr(os.WriteFile(filepath.Join(dir, "webhooker.wal"), []byte(entry(1)+"{not json}\n"+entry(2)), 0o600))
+	_, err = webhooker.NewDurableClient(dir, newWebhookRecorder(t).opt())
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "corrupt log entry"))
+}
@@ -2,11 +2,13 @@ package webhooker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
@@ -25,6 +27,11 @@ type WebhookOpts struct {
 	rateLimitRetries int
 	rateLimitDelay   time.Duration
 	contentType      ContentType
+	httpClient       *http.Client
+	transport        http.RoundTripper
+	baseURL          string
+	threadID         string
+	threadName       string
 }
 
 type WebhookOptFunc func(opts *WebhookOpts)
@@ -34,6 +41,7 @@ func DefaultWebhookOpts() *WebhookOpts {
 		rateLimitRetries: 0,
 		rateLimitDelay:   0,
 		contentType:      ContentTypeJSON,
+		baseURL:          DefaultBaseURL,
 	}
 }
 
@@ -50,206 +58,265 @@ func ContentTypeWebhookOpt(contentType ContentType) WebhookOptFunc {
 	}
 }
 
+// HTTPClientWebhookOpt sends the webhook with the client, e.g. to set timeouts, proxies or connection
+// pooling.
+func HTTPClientWebhookOpt(hc *http.Client) WebhookOptFunc {
+	return func(opts *WebhookOpts) {
+		opts.httpClient = hc
+	}
+}
+
+// TransportWebhookOpt sends the webhook with the transport, on top of the client of HTTPClientWebhookOpt when
+// both are given.
+func TransportWebhookOpt(transport http.RoundTripper) WebhookOptFunc {
+	return func(opts *WebhookOpts) {
+		opts.transport = transport
+	}
+}
+
+// BaseURLWebhookOpt sends the webhook to the base URL instead of DefaultBaseURL, e.g. a local fake of the API.
+func BaseURLWebhookOpt(baseURL string) WebhookOptFunc {
+	return func(opts *WebhookOpts) {
+		opts.baseURL = baseURL
+	}
+}
+
+// ThreadWebhookOpt sends, edits, fetches or deletes the message in the thread of the webhook's channel.
+func ThreadWebhookOpt(threadID string) WebhookOptFunc {
+	return func(opts *WebhookOpts) {
+		opts.threadID = threadID
+	}
+}
+
+// ThreadNameWebhookOpt creates a forum post with the name starting with the sent message, the webhook must
+// belong to a forum channel. Only discord.WebhookMessageCreate payloads support it, raw payloads set
+// thread_name themselves.
+func ThreadNameWebhookOpt(name string) WebhookOptFunc {
+	return func(opts *WebhookOpts) {
+		opts.threadName = name
+	}
+}
+
 func Send[T discord.WebhookMessageCreate | []byte | string](webhookURL string, payload T, opts ...WebhookOptFunc) (err error) {
+	return SendContext(context.Background(), webhookURL, payload, opts...)
+}
+
+// SendContext is Send aborting the request and rate limit waits once the context is done.
+func SendContext[T discord.WebhookMessageCreate | []byte | string](ctx context.Context, webhookURL string, payload T, opts ...WebhookOptFunc) (err error) {
 	o := DefaultWebhookOpts()
 	for _, opt := range opts {
 		opt(o)
 	}
+	bodyBytes, contentType, err := createBody(payload, o)
+	if err != nil {
+		return err
+	}
+	_, err = do(ctx, http.MethodPost, messageURL(webhookURL, "", o.threadID, false), bodyBytes, contentType, o)
+	return err
+}
 
-	var bodyBytes []byte
-	contentType := o.contentType
+func SendWithWait[T discord.WebhookMessageCreate | []byte | string](webhookURL string, payload T, opts ...WebhookOptFunc) (m discord.Message, err error) {
+	return SendWithWaitContext(context.Background(), webhookURL, payload, opts...)
+}
 
-	switch payload := any(payload).(type) {
-	case discord.WebhookMessageCreate:
-		body, err := payload.ToBody()
-		if err != nil {
-			return err
-		}
-		switch body := body.(type) {
-		case discord.WebhookMessageCreate:
-			bodyBytes, err = json.Marshal(body)
-			if err != nil {
-				return err
-			}
-		case *discord.MultipartBuffer:
-			bodyBytes = body.Buffer.Bytes()
-			contentType = ContentType(body.ContentType)
-		}
-	case string:
-		bodyBytes = []byte(payload)
-	default:
-		return fmt.Errorf("unsupported payload type")
+// SendWithWaitContext is SendWithWait aborting the request and rate limit waits once the context is done.
+func SendWithWaitContext[T discord.WebhookMessageCreate | []byte | string](ctx context.Context, webhookURL string, payload T, opts ...WebhookOptFunc) (m discord.Message, err error) {
+	o := DefaultWebhookOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+	bodyBytes, contentType, err := createBody(payload, o)
+	if err != nil {
+		return m, err
+	}
+	body, err := do(ctx, http.MethodPost, messageURL(webhookURL, "", o.threadID, true), bodyBytes, contentType, o)
+	if err != nil {
+		return m, err
 	}
+	return m, json.Unmarshal(body, &m)
+}
 
-	retries := 0
-	for {
-		client := http.Client{}
-		req, err := http.NewRequest("POST", webhookURL, bytes.NewBuffer(bodyBytes))
-		if err != nil {
-			return err
-		}
-		req.Header.Set("Content-Type", string(contentType))
-		resp, err := client.Do(req)
-		if err != nil {
-			return err
-		}
-		defer resp.Body.Close()
+func Edit[T discord.WebhookMessageUpdate | []byte | string](webhookURL string, msgID string, payload T, opts ...WebhookOptFunc) (err error) {
+	return EditContext(context.Background(), webhookURL, msgID, payload, opts...)
+}
 
-		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return err
-			}
-			if resp.StatusCode == 429 && o.rateLimitRetries > 0 && retries < o.rateLimitRetries {
-				resJ := gjson.ParseBytes(body)
-				if strings.Contains(resJ.Get("message").Str, "rate limited") {
-					secs := resJ.Get("retry_after").Float()
-					time.Sleep(time.Second*time.Duration(secs) + time.Millisecond*100 + o.rateLimitDelay)
-					retries++
-					continue
-				} else {
-					return errors.New("invalid rate limit response, status code")
-				}
-			}
-			return fmt.Errorf("failed to send webhook, status code: %d, body: %s", resp.StatusCode, string(body))
-		}
-		return nil
+// EditContext is Edit aborting the request and rate limit waits once the context is done.
+func EditContext[T discord.WebhookMessageUpdate | []byte | string](ctx context.Context, webhookURL string, msgID string, payload T, opts ...WebhookOptFunc) (err error) {
+	o := DefaultWebhookOpts()
+	for _, opt := range opts {
+		opt(o)
 	}
+	bodyBytes, contentType, err := webhookBody(payload, o.contentType)
+	if err != nil {
+		return err
+	}
+	_, err = do(ctx, http.MethodPatch, messageURL(webhookURL, msgID, o.threadID, false), bodyBytes, contentType, o)
+	return err
 }
 
-func SendWithWait[T discord.WebhookMessageCreate | []byte | string](webhookURL string, payload T, opts ...WebhookOptFunc) (m discord.Message, err error) {
+// EditWithWait is Edit returning the edited message.
+func EditWithWait[T discord.WebhookMessageUpdate | []byte | string](webhookURL string, msgID string, payload T, opts ...WebhookOptFunc) (m discord.Message, err error) {
+	return EditWithWaitContext(context.Background(), webhookURL, msgID, payload, opts...)
+}
+
+// EditWithWaitContext is EditWithWait aborting the request and rate limit waits once the context is done.
+func EditWithWaitContext[T discord.WebhookMessageUpdate | []byte | string](ctx context.Context, webhookURL string, msgID string, payload T, opts ...WebhookOptFunc) (m discord.Message, err error) {
 	o := DefaultWebhookOpts()
 	for _, opt := range opts {
 		opt(o)
 	}
-	var bodyBytes []byte
-	contentType := o.contentType
-
-	switch payload := any(payload).(type) {
-	case discord.WebhookMessageCreate:
-		body, err := payload.ToBody()
-		if err != nil {
-			return m, err
-		}
-		switch body := body.(type) {
-		case discord.WebhookMessageCreate:
-			bodyBytes, err = json.Marshal(body)
-			if err != nil {
-				return m, err
-			}
-		case *discord.MultipartBuffer:
-			bodyBytes = body.Buffer.Bytes()
-			contentType = ContentType(body.ContentType)
-		}
-	case string:
-		bodyBytes = []byte(payload)
-	default:
-		return m, fmt.Errorf("unsupported payload type")
+	bodyBytes, contentType, err := webhookBody(payload, o.contentType)
+	if err != nil {
+		return m, err
 	}
+	body, err := do(ctx, http.MethodPatch, messageURL(webhookURL, msgID, o.threadID, false), bodyBytes, contentType, o)
+	if err != nil {
+		return m, err
+	}
+	return m, json.Unmarshal(body, &m)
+}
 
-	retries := 0
-	for {
-		client := http.Client{}
-		req, err := http.NewRequest("POST", webhookURL+"?wait=true", bytes.NewBuffer(bodyBytes))
-		if err != nil {
-			return m, err
-		}
-		req.Header.Set("Content-Type", string(contentType))
-		resp, err := client.Do(req)
-		if err != nil {
-			return m, err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return m, err
-			}
-			if resp.StatusCode == 429 && o.rateLimitRetries > 0 && retries < o.rateLimitRetries {
-				resJ := gjson.ParseBytes(body)
-				if strings.Contains(resJ.Get("message").Str, "rate limited") {
-					secs := resJ.Get("retry_after").Float()
-					time.Sleep(time.Second*time.Duration(secs) + time.Millisecond*100 + o.rateLimitDelay)
-					retries++
-					continue
-				} else {
-					return m, errors.New("invalid rate limit response, status code")
-				}
-			}
-			return m, fmt.Errorf("failed to send webhook, status code: %d, body: %s", resp.StatusCode, string(body))
-		}
+// Get fetches a message sent by the webhook.
+func Get(webhookURL string, msgID string, opts ...WebhookOptFunc) (m discord.Message, err error) {
+	return GetContext(context.Background(), webhookURL, msgID, opts...)
+}
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return m, err
-		}
-		return m, json.Unmarshal(body, &m)
+// GetContext is Get aborting the request and rate limit waits once the context is done.
+func GetContext(ctx context.Context, webhookURL string, msgID string, opts ...WebhookOptFunc) (m discord.Message, err error) {
+	o := DefaultWebhookOpts()
+	for _, opt := range opts {
+		opt(o)
+	}
+	body, err := do(ctx, http.MethodGet, messageURL(webhookURL, msgID, o.threadID, false), nil, "", o)
+	if err != nil {
+		return m, err
 	}
+	return m, json.Unmarshal(body, &m)
 }
 
-func Edit[T discord.WebhookMessageUpdate | []byte | string](webhookURL string, msgID string, payload T, opts ...WebhookOptFunc) (err error) {
+// Delete deletes a message sent by the webhook.
+func Delete(webhookURL string, msgID string, opts ...WebhookOptFunc) (err error) {
+	return DeleteContext(context.Background(), webhookURL, msgID, opts...)
+}
+
+// DeleteContext is Delete aborting the request and rate limit waits once the context is done.
+func DeleteContext(ctx context.Context, webhookURL string, msgID string, opts ...WebhookOptFunc) (err error) {
 	o := DefaultWebhookOpts()
 	for _, opt := range opts {
 		opt(o)
 	}
-	var bodyBytes []byte
-	contentType := o.contentType
+	_, err = do(ctx, http.MethodDelete, messageURL(webhookURL, msgID, o.threadID, false), nil, "", o)
+	return err
+}
+
+// createBody is webhookBody setting the forum post name of ThreadNameWebhookOpt.
+func createBody[T discord.WebhookMessageCreate | []byte | string](payload T, o *WebhookOpts) (bodyBytes []byte, _ ContentType, err error) {
+	if o.threadName == "" {
+		return webhookBody(payload, o.contentType)
+	}
+	msg, ok := any(payload).(discord.WebhookMessageCreate)
+	if !ok {
+		return nil, o.contentType, errors.New("thread name requires a discord.WebhookMessageCreate payload")
+	}
+	msg.ThreadName = o.threadName
+	return webhookBody(msg, o.contentType)
+}
+
+// messageURL returns the URL of the webhook, or of its message when msgID is set, with the thread_id and wait
+// query parameters.
+func messageURL(webhookURL string, msgID string, threadID string, wait bool) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return webhookURL
+	}
+	if msgID != "" {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/messages/" + msgID
+	}
+	q := u.Query()
+	if threadID != "" {
+		q.Set("thread_id", threadID)
+	}
+	if wait {
+		q.Set("wait", "true")
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
 
+func webhookBody[T discord.WebhookMessageCreate | discord.WebhookMessageUpdate | []byte | string](payload T, contentType ContentType) (bodyBytes []byte, _ ContentType, err error) {
+	var body any
 	switch payload := any(payload).(type) {
+	case discord.WebhookMessageCreate:
+		body, err = payload.ToBody()
 	case discord.WebhookMessageUpdate:
-		body, err := payload.ToBody()
-		if err != nil {
-			return err
-		}
-		switch body := body.(type) {
-		case discord.WebhookMessageUpdate:
-			bodyBytes, err = json.Marshal(body)
-			if err != nil {
-				return err
-			}
-		case *discord.MultipartBuffer:
-			bodyBytes = body.Buffer.Bytes()
-			contentType = ContentType(body.ContentType)
-		}
+		body, err = payload.ToBody()
+	case []byte:
+		return payload, contentType, nil
 	case string:
-		bodyBytes = []byte(payload)
+		return []byte(payload), contentType, nil
 	default:
-		return fmt.Errorf("unsupported payload type")
+		return nil, contentType, fmt.Errorf("unsupported payload type")
+	}
+	if err != nil {
+		return nil, contentType, err
+	}
+	if body, ok := body.(*discord.MultipartBuffer); ok {
+		return body.Buffer.Bytes(), ContentType(body.ContentType), nil
 	}
+	bodyBytes, err = json.Marshal(body)
+	return bodyBytes, contentType, err
+}
 
+// do sends the request, retrying rate limited ones as the options allow, and returns the response body.
+func do(ctx context.Context, method string, url string, bodyBytes []byte, contentType ContentType, o *WebhookOpts) (body []byte, err error) {
+	client := newHTTPClient(o.httpClient, o.transport)
+	url = rewriteBaseURL(url, o.baseURL)
 	retries := 0
 	for {
-		client := http.Client{}
-		req, err := http.NewRequest("PATCH", fmt.Sprintf("%s/messages/%s", webhookURL, msgID), bytes.NewBuffer(bodyBytes))
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(bodyBytes))
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if bodyBytes != nil {
+			req.Header.Set("Content-Type", string(contentType))
 		}
-		req.Header.Set("Content-Type", string(contentType))
 		resp, err := client.Do(req)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return err
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK {
+			return body, nil
+		}
+		if resp.StatusCode == 429 && o.rateLimitRetries > 0 && retries < o.rateLimitRetries {
+			resJ := gjson.ParseBytes(body)
+			if !strings.Contains(resJ.Get("message").Str, "rate limited") {
+				return nil, errors.New("invalid rate limit response, status code")
 			}
-			if resp.StatusCode == 429 && o.rateLimitRetries > 0 && retries < o.rateLimitRetries {
-				resJ := gjson.ParseBytes(body)
-				if strings.Contains(resJ.Get("message").Str, "rate limited") {
-					secs := resJ.Get("retry_after").Float()
-					time.Sleep(time.Second*time.Duration(secs) + time.Millisecond*100 + o.rateLimitDelay)
-					retries++
-					continue
-				} else {
-					return errors.New("invalid rate limit response, status code")
-				}
+			secs := resJ.Get("retry_after").Float()
+			err = sleepContext(ctx, time.Second*time.Duration(secs)+time.Millisecond*100+o.rateLimitDelay)
+			if err != nil {
+				return nil, err
 			}
-			return fmt.Errorf("failed to send webhook, status code: %d, body: %s", resp.StatusCode, string(body))
+			retries++
+			continue
 		}
+		return nil, fmt.Errorf("failed to send webhook, status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+}
+
+// sleepContext sleeps for the duration unless the context is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
 		return nil
 	}
 }
@@ -1,64 +1,153 @@
 package webhooker
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"strings"
+	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/disgoorg/disgo/discord"
+	disgo_webhook "github.com/disgoorg/disgo/webhook"
 	"github.com/go-resty/resty/v2"
 	"github.com/tidwall/gjson"
 )
 
 type webhooker struct {
-	ch  chan sendMsg
-	url string
+	ch      chan sendMsg
+	url     string
+	pending *pendingCounter
+	// queued counts the messages waiting in a queue for this webhook.
+	queued atomic.Int64
+	wal    *wal
+	rc     *resty.Client
 	*ClientOpts
 }
 
 func (c *Client) newWebhooker(url string) (w *webhooker) {
 	w = &webhooker{
-		ch:         make(chan sendMsg),
+		ch:         make(chan sendMsg, c.queueSize),
 		url:        url,
+		pending:    c.pending,
+		wal:        c.wal,
+		rc:         c.rc,
 		ClientOpts: c.ClientOpts,
 	}
 	return
 }
 
+// webhooker returns the webhooker of the URL, starting it on the first send.
+func (c *Client) webhooker(webhookURL string) (w *webhooker, err error) {
+	c.webhookersMu.Lock()
+	defer c.webhookersMu.Unlock()
+	if w, ok := c.recChMap.Get(webhookURL); ok {
+		return w, nil
+	}
+	wc, err := disgo_webhook.NewWithURL(webhookURL)
+	if err != nil {
+		return nil, err
+	}
+	w = c.newWebhooker(rewriteBaseURL(wc.URL(), c.baseURL))
+	c.recChMap.Set(webhookURL, w)
+	c.workers.Add(1)
+	go func() {
+		defer c.workers.Done()
+		w.listen()
+	}()
+	return w, nil
+}
+
 func (w *webhooker) listen() {
 	for msg := range w.ch {
-		w.send(msg)
+		w.deliver(msg)
 	}
 }
 
-func (w *webhooker) send(msg sendMsg) {
-	body, contentType, err := ParseWebhook(msg.msg)
-	if err != nil {
-		if w.errCh != nil {
-			w.errCh <- err
-		}
-		if w.enableLogging {
-			w.logger.Error("failed to parse webhook", slog.Any("err", err))
-		}
+// deliver sends the message and resolves its delivery.
+func (w *webhooker) deliver(msg sendMsg) {
+	w.queued.Add(-1)
+	if msg.replayed != nil {
+		msg.replayed.Done()
+	}
+	defer w.pending.done()
+	m, err := w.send(msg)
+	w.ack(msg)
+	if msg.delivery != nil {
+		msg.delivery.resolve(m, err)
+	}
+	if msg.opts.Callback != nil {
+		msg.opts.Callback(m, err)
+	}
+}
+
+// ack marks the message as done in the durable queue.
+func (w *webhooker) ack(msg sendMsg) {
+	if w.wal == nil || msg.walID == 0 {
 		return
 	}
+	err := w.wal.ack(msg.walID)
+	if err != nil && w.enableLogging {
+		w.logger.Error("failed to ack webhook in the durable queue", slog.Any("err", err))
+	}
+}
 
-	rc := resty.New()
+func (w *webhooker) send(msg sendMsg) (m *discord.Message, err error) {
+	wait := msg.delivery != nil || msg.opts.Callback != nil || msg.method == http.MethodGet
+	ctx := msg.opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	method := msg.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	reqURL := w.url
+	if msg.msgID != "" {
+		reqURL += "/messages/" + msg.msgID
+	}
+	// message routes have their own buckets
+	route := w.url
+	if method != http.MethodPost {
+		route = method + " " + w.url + "/messages"
+	}
 
 	retries := 0
 	rateLimitRetries := 0
+	rateLimited := false
 	for retries <= w.maxRetries && (w.maxRateLimitRetries == -1 || rateLimitRetries <= w.maxRateLimitRetries) {
-		if retries > 0 || rateLimitRetries > 0 {
+		if retries > 0 && !rateLimited {
 			if w.enableLogging {
 				w.logger.Warn("retrying webhook", slog.Any("retries", retries), slog.Any("rateLimitRetries", rateLimitRetries), slog.Any("delay", w.errDelay))
 			}
-			time.Sleep(w.errDelay)
+			if err = sleepContext(ctx, w.errDelay); err != nil {
+				return nil, err
+			}
 		}
-		res, err := rc.R().SetBody(body).SetHeader("Content-Type", contentType).Post(w.url)
+		rateLimited = false
+		err = w.rateLimiter.Wait(ctx, route)
 		if err != nil {
+			return nil, err
+		}
+		req := w.rc.R().SetContext(ctx)
+		if msg.body != nil {
+			req.SetBody(msg.body).SetHeader("Content-Type", msg.contentType)
+		}
+		if msg.opts.ThreadID != "" {
+			req.SetQueryParam("thread_id", msg.opts.ThreadID)
+		}
+		if wait && method == http.MethodPost {
+			req.SetQueryParam("wait", "true")
+		}
+		res, resErr := req.Execute(method, reqURL)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if resErr != nil {
 			retries++
+			err = resErr
 			if w.errCh != nil {
 				w.errCh <- err
 			}
@@ -67,14 +156,21 @@ func (w *webhooker) send(msg sendMsg) {
 			}
 			continue
 		}
-		resJ := gjson.ParseBytes(res.Body())
-		if res.StatusCode() == 429 && strings.Contains(resJ.Get("message").Str, "rate limited") {
+		retryAfter := w.rateLimiter.Update(route, res.StatusCode(), res.Header())
+		if res.StatusCode() == 429 {
+			rateLimited = true
 			rateLimitRetries++
-			secs := resJ.Get("retry_after").Float()
-			delay := time.Second*time.Duration(secs) + time.Millisecond*500
-			time.Sleep(delay)
+			if retryAfter == 0 {
+				// no Retry-After header, fall back to the body
+				secs := gjson.ParseBytes(res.Body()).Get("retry_after").Float()
+				retryAfter = time.Duration(secs*float64(time.Second)) + time.Millisecond*500
+				if err = sleepContext(ctx, retryAfter); err != nil {
+					return nil, err
+				}
+			}
+			err = fmt.Errorf("rate limited, retrying in %s", retryAfter)
 			if w.errCh != nil {
-				w.errCh <- fmt.Errorf("rate limited, retrying in %s", delay)
+				w.errCh <- err
 			}
 			if w.enableLogging {
 				w.logger.Error("webhook rate limited", slog.Any("body", res.Body()))
@@ -82,19 +178,28 @@ func (w *webhooker) send(msg sendMsg) {
 			continue
 		} else if res.StatusCode() != 204 && res.StatusCode() != 200 {
 			retries++
+			err = fmt.Errorf("failed to send webhook, status code: %d", res.StatusCode())
 			if w.errCh != nil {
-				w.errCh <- fmt.Errorf("failed to send webhook, status code: %d", res.StatusCode())
+				w.errCh <- err
 			}
 			if w.enableLogging {
 				w.logger.Error("failed to send webhook", slog.Any("body", res.Body()), slog.Any("status", res.StatusCode()))
 			}
 			continue
 		}
-		break
-	}
-	if w.enableLogging {
-		w.logger.Info("sent webhook")
+		if w.enableLogging {
+			w.logger.Info("sent webhook")
+		}
+		if !wait || res.StatusCode() == 204 {
+			return nil, nil
+		}
+		m = &discord.Message{}
+		if err = json.Unmarshal(res.Body(), m); err != nil {
+			return nil, err
+		}
+		return m, nil
 	}
+	return nil, fmt.Errorf("%w: %w", ErrRetriesExhausted, err)
 }
 
 func ParseWebhook(webhook discord.WebhookMessageCreate) (body []byte, contentType string, err error) {